@@ -0,0 +1,108 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ysqi/gcodesharp/config"
+	"github.com/ysqi/gcodesharp/reporter"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultBaseline is the baseline file name in project root dir.
+const defaultBaseline = ".gcodesharp-baseline.json"
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage the baseline of known findings",
+	Long: `The baseline is the known findings of project, e.g: the lint problems of legacy code.
+The finding in baseline is suppressed by "gcodesharp --baseline file", so only the new finding is reported.
+The failed test is never suppressed.`,
+}
+
+var baselineSaveCmd = &cobra.Command{
+	Use:   "save [packages]",
+	Short: "Run the tools and save the current findings as baseline",
+	Long: `Run the tools and save the current findings as baseline.
+The file is the --baseline flag, or the baseline of config file, default is ` + defaultBaseline + ` in project root dir.`,
+	Args: cobra.ArbitraryArgs,
+	Run:  runBaselineSave,
+}
+
+func init() {
+	baselineCmd.AddCommand(baselineSaveCmd)
+	rootCmd.AddCommand(baselineCmd)
+}
+
+func runBaselineSave(c *cobra.Command, args []string) {
+	rp, cfg, err := runReporter(c, args)
+	if err != nil {
+		// the baseline of partial result is not complete.
+		fatalf("run:%s", err)
+	}
+	b, err := rp.Baseline()
+	if err != nil {
+		fatalf("create baseline:%s", err)
+	}
+	file := baselineFile(cfg)
+	f, err := os.Create(file)
+	if err != nil {
+		fatalf("save baseline:%s", err)
+	}
+	defer f.Close()
+	if err := b.Write(f); err != nil {
+		fatalf("save baseline:%s", err)
+	}
+	log.Printf("%d findings are saved to baseline %s", len(b.Findings), file)
+}
+
+// baselineFile return the baseline file to save.
+func baselineFile(cfg *config.Config) string {
+	if baselinepath != "" {
+		return baselinepath
+	}
+	return filepath.Join(cfg.Dir(), defaultBaseline)
+}
+
+// applyBaseline suppress the known findings in baseline file and print the fixed entries.
+func applyBaseline(rp *reporter.Reporter, file string) {
+	f, err := os.Open(file)
+	if err != nil {
+		fatalf("load baseline:%s", err)
+	}
+	defer f.Close()
+	b, err := reporter.ReadBaseline(f)
+	if err != nil {
+		fatalf("load baseline %s:%s", file, err)
+	}
+	result, err := rp.ApplyBaseline(b)
+	if err != nil {
+		fatalf("apply baseline:%s", err)
+	}
+	log.Printf("%d known findings are suppressed by baseline %s", result.Suppressed, file)
+	if len(result.Fixed) == 0 {
+		return
+	}
+	fmt.Printf("\n%d baseline findings are fixed, run \"gcodesharp baseline save\" to update the baseline:\n", len(result.Fixed))
+	for _, e := range result.Fixed {
+		fmt.Printf("  %s [%s/%s] %s\n", e.Position(), e.Tool, e.Rule, e.Message)
+	}
+}
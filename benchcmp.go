@@ -0,0 +1,126 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ysqi/gcodesharp/gtest"
+
+	"github.com/spf13/cobra"
+)
+
+var benchcmpCmd = &cobra.Command{
+	Use:   "benchcmp old.txt new.txt",
+	Short: "Compare the benchmark results of two go test runs",
+	Long: `Compare the benchmark results of two captured "go test -bench -count N" outputs.
+The ns/op, B/op and allocs/op of each benchmark are compared with the 95% confidence interval,
+and exit with code 1 if any benchmark regresses past the threshold.`,
+	Args: cobra.ExactArgs(2),
+	Run:  runBenchcmp,
+}
+
+// benchcmpConfig is the "benchcmp" section of config file.
+type benchcmpConfig struct {
+	Threshold float64 `yaml:"threshold"` // the max percent of regression, e.g: 5
+}
+
+var threshold float64 // the max percent of regression
+
+func init() {
+	benchcmpCmd.Flags().Float64Var(&threshold, "threshold", 5, `the max percent of significant regression`)
+	rootCmd.AddCommand(benchcmpCmd)
+}
+
+func runBenchcmp(c *cobra.Command, args []string) {
+	cfg := loadConfig(c)
+	if !c.Flags().Changed("threshold") {
+		bc := benchcmpConfig{Threshold: threshold}
+		if err := cfg.Section("benchcmp", &bc); err != nil {
+			fatalf("load config:%s", err)
+		}
+		threshold = bc.Threshold
+	}
+	before, err := loadBenchmarks(args[0])
+	if err != nil {
+		fatalf("benchcmp:%s", err)
+	}
+	after, err := loadBenchmarks(args[1])
+	if err != nil {
+		fatalf("benchcmp:%s", err)
+	}
+	deltas := gtest.CompareBenchmarks(before, after)
+	if len(deltas) == 0 {
+		fatalf("benchcmp: no benchmark found in both %s and %s", args[0], args[1])
+	}
+
+	regressed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "package\tbenchmark\tunit\told\tnew\tdelta\t95% CI\t")
+	for _, d := range deltas {
+		mark := "~"
+		switch {
+		case d.Regressed(threshold):
+			mark = "REGRESSION"
+			regressed++
+		case d.Improved():
+			mark = "improved"
+		case d.Significant:
+			mark = "changed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.Package, d.Name, d.Unit,
+			formatStat(d.Old), formatStat(d.New), formatPct(d.Delta),
+			"["+formatPct(d.Low)+", "+formatPct(d.High)+"]", mark)
+	}
+	if err := w.Flush(); err != nil {
+		fatalf("benchcmp:%s", err)
+	}
+	if regressed > 0 {
+		fmt.Printf("\n%d benchmark metrics regress more than %g%%\n", regressed, threshold)
+		os.Exit(exitGateFailed)
+	}
+}
+
+// loadBenchmarks parse the benchmark results of go test output file.
+func loadBenchmarks(file string) (map[string][]*gtest.Benchmark, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return gtest.ParseBenchmarks(f)
+}
+
+// formatStat format the mean and the coefficient of variation, e.g: 1234 ± 2%
+func formatStat(s gtest.BenchStat) string {
+	if s.N < 2 {
+		return fmt.Sprintf("%.4g", s.Mean)
+	}
+	return fmt.Sprintf("%.4g ± %.0f%%", s.Mean, s.CV())
+}
+
+func formatPct(v float64) string {
+	if math.IsInf(v, 0) {
+		if v > 0 {
+			return "+inf"
+		}
+		return "-inf"
+	}
+	return fmt.Sprintf("%+.2f%%", v)
+}
@@ -16,14 +16,21 @@
 package main
 
 import (
-	"go/build"
+	gocontext "context"
+	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/ysqi/gcodesharp/config"
 	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/gbuild"
 	"github.com/ysqi/gcodesharp/gfmt"
 	"github.com/ysqi/gcodesharp/glint"
 	"github.com/ysqi/gcodesharp/gtest"
+	"github.com/ysqi/gcodesharp/gvet"
 	"github.com/ysqi/gcodesharp/reporter"
 
 	"github.com/spf13/cobra"
@@ -35,19 +42,69 @@ var rootCmd = &cobra.Command{
 	Short: "A mini sharp tool for go code review",
 	Long: `GCodeSharp is a CLI library for Go language code review applications.
 This application is a tool to generate the report to quickly review the golang code.`,
-	Run: run,
+	// the args are the packages to review, not the sub command.
+	Args: cobra.ArbitraryArgs,
+	Run:  run,
 }
 
 var (
 	junitpath string // enable save report to xml file
+	htmlpath  string // enable save report to html file
+	sarifpath string // enable save report to sarif file
+	stylepath string // enable save report to checkstyle xml file
+	coberpath string // enable save coverage to cobertura xml file
+	lcovpath  string // enable save coverage to lcov file
+	textpath  string // enable save report to text file, "-" is stdout
+	jsonpath  string // enable save report to json file
+	cfgpath   string // the config file, find .gcodesharp.yml from working dir if not set
+	record    bool   // enable save the result to history store
+
+	baselinepath string // the baseline file, the finding in it is suppressed
+	since        string // the git ref of diff-aware mode, only the changes since it are checked
+	timeout      time.Duration
+	parallel     int  // the max number of running jobs, 0 is GOMAXPROCS
+	quiet        bool // only print the failures
 
 	selectTool  []string
-	defaultTool = []string{"gtest", "gfmt", "glint"}
+	defaultTool = []string{"gtest", "gfmt", "glint", "gvet"}
+	// the optional tools are only run if they are selected.
+	allTool = append([]string{"gbuild"}, defaultTool...)
+)
+
+// The exit code of gcodesharp, CI can block by it.
+const (
+	exitGateFailed = 1 // the report does not pass the quality gates
+	exitCrashed    = 2 // the tool or gcodesharp self crashed
 )
 
+// fatalf print the error and exit with exitCrashed.
+func fatalf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+	os.Exit(exitCrashed)
+}
+
+// errorf print the error of tool, the tool is stopped by itself.
+func errorf(format string, args ...interface{}) {
+	log.Printf("[ERROR] "+format, args...)
+}
+
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&junitpath, "junit", "j", "", `save report as junit xml file`)
+	rootCmd.PersistentFlags().StringVar(&htmlpath, "html", "", `save report as a self-contained html file`)
+	rootCmd.PersistentFlags().StringVar(&sarifpath, "sarif", "", `save report as sarif 2.1.0 json file`)
+	rootCmd.PersistentFlags().StringVar(&stylepath, "checkstyle", "", `save report as checkstyle xml file`)
+	rootCmd.PersistentFlags().StringVar(&coberpath, "cobertura", "", `save test coverage as cobertura xml file`)
+	rootCmd.PersistentFlags().StringVar(&lcovpath, "lcov", "", `save test coverage as lcov file`)
+	rootCmd.PersistentFlags().StringVar(&textpath, "text", "", `save report as plain text file, "-" is stdout`)
+	rootCmd.PersistentFlags().StringVar(&jsonpath, "json", "", `save report as json file, see reporter.ReadJSON to read it`)
+	rootCmd.Flags().BoolVar(&record, "record", false, `save the result to history store, see "gcodesharp history"`)
+	rootCmd.PersistentFlags().StringVar(&baselinepath, "baseline", "", `suppress the known findings in baseline file, see "gcodesharp baseline save"`)
+	rootCmd.PersistentFlags().StringVar(&since, "since", "", `only check the packages and lines changed since the git ref, e.g: origin/master`)
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, `stop the tools if they run longer than it and report the partial result, e.g: 10m (default no timeout)`)
+	rootCmd.PersistentFlags().IntVar(&parallel, "parallel", 0, `the max number of packages which are checked at the same time (default GOMAXPROCS)`)
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, `only print the failures, e.g: for CI logs (default show the progress bars on terminal)`)
 	rootCmd.PersistentFlags().StringArrayVarP(&selectTool, "tool", "t", defaultTool, `specify which tool to exec`)
+	rootCmd.PersistentFlags().StringVarP(&cfgpath, "config", "c", "", `the config file (default is `+config.FileName+` in working dir or its parent dir)`)
 }
 
 func include(array []string, s string) bool {
@@ -60,85 +117,309 @@ func include(array []string, s string) bool {
 }
 
 func run(c *cobra.Command, args []string) {
-	sCtx := initCtx(c, args...)
+	rp, cfg, runErr := runReporter(c, args)
+	if baselinepath != "" {
+		applyBaseline(rp, baselinepath)
+	}
+
+	err := saveTestReport(rp)
+	if err != nil {
+		fatalf("create and save junit:%s", err.Error())
+	}
+	err = saveHTMLReport(rp)
+	if err != nil {
+		fatalf("create and save html:%s", err.Error())
+	}
+	err = saveSARIFReport(rp)
+	if err != nil {
+		fatalf("create and save sarif:%s", err.Error())
+	}
+	err = saveCheckstyleReport(rp)
+	if err != nil {
+		fatalf("create and save checkstyle:%s", err.Error())
+	}
+	err = saveCoverageReport(rp)
+	if err != nil {
+		fatalf("create and save coverage:%s", err.Error())
+	}
+	err = saveTextReport(rp)
+	if err != nil {
+		fatalf("create and save text:%s", err.Error())
+	}
+	err = saveJSONReport(rp)
+	if err != nil {
+		fatalf("create and save json:%s", err.Error())
+	}
+	if runErr != nil {
+		// the partial result is saved, but it is not recorded and checked.
+		fatalf("run:%s", runErr)
+	}
+	if record {
+		if err = recordHistory(rp, cfg); err != nil {
+			fatalf("record history:%s", err.Error())
+		}
+	}
+	if !checkGates(rp, cfg) {
+		os.Exit(exitGateFailed)
+	}
+}
+
+// runReporter load the config, run the selected tools and wait for all of them done.
+// the tools are killed if timeout, interrupted or any tool failed,
+// and the error is returned with the reporter which has the partial result.
+func runReporter(c *cobra.Command, args []string) (*reporter.Reporter, *config.Config, error) {
+	cfg := loadConfig(c)
+	sCtx := initCtx(c, cfg, args...)
 	rp, err := reporter.New(sCtx)
 	if err != nil {
-		log.Fatalf(err.Error())
+		fatalf("%s", err)
 	}
 
+	if include(selectTool, "gbuild") {
+		regGoBuildService(rp)
+	}
 	if include(selectTool, "gfmt") {
 		regGoFormatService(rp)
 	}
 	if include(selectTool, "glint") {
 		regGolintService(rp)
 	}
+	if include(selectTool, "gvet") {
+		regGoVetService(rp)
+	}
 	if include(selectTool, "gtest") {
 		regGoTestService(rp)
 	}
 	if rp.RegisterNumber() == 0 {
-		log.Fatalf("does not contain a valid tool, stop running. all tool: %s", defaultTool)
+		fatalf("does not contain a valid tool, stop running. all tool: %s", allTool)
 	}
-	err = rp.Start()
+	var (
+		runCtx = gocontext.Background()
+		cancel gocontext.CancelFunc
+	)
+	if timeout > 0 {
+		runCtx, cancel = gocontext.WithTimeout(runCtx, timeout)
+	} else {
+		runCtx, cancel = gocontext.WithCancel(runCtx)
+	}
+	defer cancel()
+	// Ctrl-C and SIGTERM stop the tools, and the partial result is still reported.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		select {
+		case sig := <-sigs:
+			log.Printf("[WARN] received %s, stop the running tools", sig)
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	ui := newProgress(os.Stderr, quiet)
+	ui.start(rp.Events())
+	err = rp.Start(runCtx)
 	if err != nil {
-		log.Fatalf("start reporter:%s", err.Error())
+		fatalf("start reporter:%s", err.Error())
 	}
-	rp.Wait()
+	err = rp.Wait()
+	ui.stop()
+	return rp, cfg, err
+}
 
-	err = saveTestReport(rp)
+// checkGates check the quality gates of config and print the summary,
+// the failed tests and packages are checked if no gate is set.
+// return false if any gate failed.
+func checkGates(rp *reporter.Reporter, cfg *config.Config) bool {
+	gates := reporter.GatesFromConfig(cfg.Gates)
+	results, err := rp.CheckGates(gates)
 	if err != nil {
-		log.Fatalf("create and save junit:%s", err.Error())
+		fatalf("check quality gates:%s", err)
+	}
+	fmt.Println()
+	if err := reporter.WriteGateSummary(os.Stdout, results); err != nil {
+		fatalf("print quality gates:%s", err)
 	}
+	return reporter.GatesPassed(results)
 }
 
-func initCtx(c *cobra.Command, packages ...string) *reporter.ServiceContext {
+// loadConfig load the config file, and the flags which is set by user will overwrite the config.
+func loadConfig(c *cobra.Command) *config.Config {
+	var (
+		cfg *config.Config
+		err error
+	)
+	if cfgpath != "" {
+		cfg, err = config.Load(cfgpath)
+	} else {
+		cfg, err = config.Discover(".")
+	}
+	if err != nil {
+		fatalf("load config:%s", err)
+	}
+	if cfg.File != "" {
+		log.Printf("using config file %s", cfg.File)
+	}
+	if !c.Flags().Changed("tool") && len(cfg.Tools) > 0 {
+		selectTool = cfg.Tools
+	}
+	if junitpath == "" {
+		junitpath = cfg.Output.JUnit
+	}
+	if htmlpath == "" {
+		htmlpath = cfg.Output.HTML
+	}
+	if sarifpath == "" {
+		sarifpath = cfg.Output.SARIF
+	}
+	if stylepath == "" {
+		stylepath = cfg.Output.Checkstyle
+	}
+	if coberpath == "" {
+		coberpath = cfg.Output.Cobertura
+	}
+	if lcovpath == "" {
+		lcovpath = cfg.Output.LCOV
+	}
+	if textpath == "" {
+		textpath = cfg.Output.Text
+	}
+	if jsonpath == "" {
+		jsonpath = cfg.Output.JSON
+	}
+	if !record {
+		record = cfg.History.Record
+	}
+	if baselinepath == "" {
+		baselinepath = cfg.Baseline
+	}
+	if parallel <= 0 {
+		parallel = cfg.Parallel
+	}
+	return cfg
+}
+
+func initCtx(c *cobra.Command, cfg *config.Config, packages ...string) *reporter.ServiceContext {
+	// the dir to find packages, empty is current dir.
+	dir := ""
 	if len(packages) == 0 {
-		log.Println("[WARN] No package path is set and will use current dir as package path")
-		packages = append(packages, ".")
+		if cfg.File != "" {
+			// check the whole project which the config file in.
+			dir = cfg.Dir()
+			packages = append(packages, "./...")
+		} else {
+			log.Println("[WARN] No package path is set and will use current dir as package path")
+			packages = append(packages, ".")
+		}
 	}
 	added := map[string]struct{}{}
-	appendPkg := func(path string) {
-		p, err := build.Import(path, "", build.IgnoreVendor)
-		if err != nil {
-			log.Fatalf("initCtx:%s", err)
+	appendPkg := func(p *context.Package) {
+		// repeat clear, the dir is empty if the package can not be found.
+		key := p.Dir
+		if key == "" {
+			key = p.ImportPath
 		}
-		// repeat clear
-		if _, ok := added[p.Dir]; !ok {
+		if _, ok := added[key]; !ok {
 			ctx.Packages = append(ctx.Packages, p)
-			added[p.Dir] = struct{}{}
+			added[key] = struct{}{}
 		}
 	}
 	for _, p := range packages {
 		// find all package in dir by go list command.
-		list, err := context.GetPackagePaths(p)
+		// it works with module mode and GOPATH mode.
+		list, err := context.ListPackages(dir, p)
 		if err != nil {
-			log.Fatalf("initCtx:%s", err)
+			fatalf("initCtx:%s", err)
 		}
 		for _, p := range list {
+			if p.Dir != "" && !cfg.Match(p.Dir) {
+				continue
+			}
+			// the package can not be loaded is still handled,
+			// and its load error will be reported by service.
+			if p.HasLoadError() {
+				log.Printf("[WARN] load package %s:%s", p.ImportPath, p.LoadError())
+			}
 			appendPkg(p)
 		}
 	}
+	if since != "" {
+		// diff-aware mode
+		changes, err := context.GitChanges(cfg.Dir(), since)
+		if err != nil {
+			fatalf("find changes since %s:%s", since, err)
+		}
+		all := len(ctx.Packages)
+		ctx.SetChanges(changes)
+		log.Printf("%d files changed since %s, %d of %d packages are affected", len(changes), since, len(ctx.Packages), all)
+	}
 
 	return &reporter.ServiceContext{
 		GlobalCxt: ctx,
 		Flagset:   c.Flags(),
-		ErrH:      log.Fatalf,
+		Config:    cfg,
+		Scheduler: reporter.NewScheduler(parallel, cfg.ToolParallel),
+		Events:    reporter.NewEventBus(),
+		ErrH:      errorf,
 	}
 }
 
+func regGoBuildService(rep *reporter.Reporter) {
+	rep.Register(func(ctx *reporter.ServiceContext) (reporter.Service, error) {
+		s, err := gbuild.New(ctx.GlobalCxt, ctx.ErrH)
+		if err != nil {
+			return nil, err
+		}
+		s.Scheduler = ctx.Scheduler
+		s.Events = ctx.Events
+		return s, nil
+	})
+}
+
 func regGolintService(rep *reporter.Reporter) {
 	rep.Register(func(ctx *reporter.ServiceContext) (reporter.Service, error) {
-		return glint.New(ctx.GlobalCxt, ctx.ErrH)
+		s, err := glint.New(ctx.GlobalCxt, ctx.ErrH)
+		if err != nil {
+			return nil, err
+		}
+		s.Scheduler = ctx.Scheduler
+		s.Events = ctx.Events
+		return s, ctx.Config.Section("glint", &s.Config)
 	})
 }
 func regGoFormatService(rep *reporter.Reporter) {
 	rep.Register(func(ctx *reporter.ServiceContext) (reporter.Service, error) {
-		return gfmt.New(ctx.GlobalCxt, ctx.ErrH)
+		s, err := gfmt.New(ctx.GlobalCxt, ctx.ErrH)
+		if err != nil {
+			return nil, err
+		}
+		s.Scheduler = ctx.Scheduler
+		s.Events = ctx.Events
+		return s, ctx.Config.Section("gfmt", &s.Config)
+	})
+}
+
+func regGoVetService(rep *reporter.Reporter) {
+	rep.Register(func(ctx *reporter.ServiceContext) (reporter.Service, error) {
+		s, err := gvet.New(ctx.GlobalCxt, ctx.ErrH)
+		if err != nil {
+			return nil, err
+		}
+		s.Scheduler = ctx.Scheduler
+		s.Events = ctx.Events
+		return s, ctx.Config.Section("gvet", &s.Config)
 	})
 }
 
 func regGoTestService(rep *reporter.Reporter) {
 	rep.Register(func(ctx *reporter.ServiceContext) (reporter.Service, error) {
-		return gtest.New(ctx.GlobalCxt, ctx.ErrH)
+		s, err := gtest.New(ctx.GlobalCxt, ctx.ErrH)
+		if err != nil {
+			return nil, err
+		}
+		s.Scheduler = ctx.Scheduler
+		s.Events = ctx.Events
+		return s, ctx.Config.Section("gtest", &s.Config)
 	})
 }
 
@@ -157,3 +438,106 @@ func saveTestReport(report *reporter.Reporter) error {
 	}()
 	return report.OutputJunit(false, f)
 }
+
+func saveHTMLReport(report *reporter.Reporter) error {
+	if htmlpath == "" {
+		return nil
+	}
+
+	f, err := os.Create(htmlpath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+	}()
+	return report.OutputHTML(f)
+}
+
+func saveSARIFReport(report *reporter.Reporter) error {
+	if sarifpath == "" {
+		return nil
+	}
+
+	f, err := os.Create(sarifpath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+	}()
+	return report.OutputSARIF(f)
+}
+
+func saveCheckstyleReport(report *reporter.Reporter) error {
+	if stylepath == "" {
+		return nil
+	}
+
+	f, err := os.Create(stylepath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+	}()
+	return report.OutputCheckstyle(false, f)
+}
+
+func saveCoverageReport(report *reporter.Reporter) error {
+	if coberpath != "" {
+		f, err := os.Create(coberpath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := report.OutputCobertura(false, f); err != nil {
+			return err
+		}
+	}
+	if lcovpath != "" {
+		f, err := os.Create(lcovpath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := report.OutputLCOV(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func saveTextReport(report *reporter.Reporter) error {
+	if textpath == "" {
+		return nil
+	}
+	if textpath == "-" {
+		fmt.Println()
+		return report.OutputText(os.Stdout)
+	}
+
+	f, err := os.Create(textpath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+	}()
+	return report.OutputText(f)
+}
+
+func saveJSONReport(report *reporter.Reporter) error {
+	if jsonpath == "" {
+		return nil
+	}
+
+	f, err := os.Create(jsonpath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		f.Close()
+	}()
+	return report.OutputJSON(f)
+}
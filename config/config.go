@@ -0,0 +1,291 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package config is the project config of gcodesharp,
+// the config file named .gcodesharp.yml and it is found from working dir and its parent dir.
+//
+// e.g:
+//
+//	tools: [gtest, gfmt, glint, gvet]
+//	include: ["./..."]
+//	exclude: ["vendor/**", "**/testdata/**"]
+//	output:
+//	  junit: junit.xml
+//	  html: report.html
+//	parallel: 4
+//	tool_parallel:
+//	  gtest: 2
+//	gtest:
+//	  tags: [integration]
+//	  timeout: 30s
+//	  race: true
+//	gfmt:
+//	  simplify: true
+//	gates:
+//	  max_failed_tests: 0
+//	  min_coverage: 60
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FileName is the config file name of project.
+const FileName = ".gcodesharp.yml"
+
+// Output the report output destinations, the relative path is relative to the config file dir.
+type Output struct {
+	JUnit      string `yaml:"junit"`
+	HTML       string `yaml:"html"`
+	SARIF      string `yaml:"sarif"`
+	Checkstyle string `yaml:"checkstyle"`
+	Cobertura  string `yaml:"cobertura"`
+	LCOV       string `yaml:"lcov"`
+	Text       string `yaml:"text"` // "-" is stdout
+	JSON       string `yaml:"json"`
+}
+
+// Gates the quality gates which are checked after all tools done,
+// the gate is disabled if it is not set.
+type Gates struct {
+	MaxFailedTests      *float64 `yaml:"max_failed_tests"`
+	MaxFailedPackages   *float64 `yaml:"max_failed_packages"`
+	MinCoverage         *float64 `yaml:"min_coverage"`       // minimum coverage percent of each package
+	MinTotalCoverage    *float64 `yaml:"min_total_coverage"` // minimum coverage percent of all packages
+	MaxUnformattedFiles *float64 `yaml:"max_unformatted_files"`
+	MaxLintProblems     *float64 `yaml:"max_lint_problems"`
+	MaxVetProblems      *float64 `yaml:"max_vet_problems"`
+}
+
+// History the history store of run results.
+type History struct {
+	Record bool   `yaml:"record"` // save the result of each run to history store
+	Dir    string `yaml:"dir"`    // the store dir, default is .gcodesharp/history in the config file dir
+}
+
+// Config is the project config.
+type Config struct {
+	// File is the path of config file, empty if the config is not loaded from file.
+	File string `yaml:"-"`
+
+	// Tools enabled tools, all tools is enabled if empty.
+	Tools []string `yaml:"tools"`
+	// Include and Exclude are the package dir globs which relative to the config file dir,
+	// the "**" matches any number of dir, e.g: vendor/** , **/testdata
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+
+	Output Output `yaml:"output"`
+
+	Gates Gates `yaml:"gates"`
+
+	History History `yaml:"history"`
+
+	// Parallel is the max number of jobs which run at the same time, default is GOMAXPROCS.
+	// the job is a tool checks a package, e.g: go test of a package.
+	Parallel int `yaml:"parallel"`
+	// ToolParallel is the max number of running jobs of each tool, e.g: {gtest: 2}.
+	// the tool is not capped if not set, but it is still limited by Parallel.
+	ToolParallel map[string]int `yaml:"tool_parallel"`
+
+	// Baseline is the baseline file, the finding in it is suppressed.
+	// it is created by "gcodesharp baseline save".
+	Baseline string `yaml:"baseline"`
+
+	// Sections is the options of each tool, the key is the tool name, e.g: gtest.
+	// the service read its section by Section method.
+	Sections map[string]interface{} `yaml:",inline"`
+}
+
+// Default return a empty config which use the default value of all things.
+func Default() *Config {
+	return &Config{}
+}
+
+// Find walk up from dir to find the config file, return empty if not found.
+func Find(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		file := filepath.Join(dir, FileName)
+		if fi, err := os.Stat(file); err == nil && !fi.IsDir() {
+			return file
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Discover find the config file from dir and load it,
+// return the default config if not found.
+func Discover(dir string) (*Config, error) {
+	file := Find(dir)
+	if file == "" {
+		return Default(), nil
+	}
+	return Load(file)
+}
+
+// Load read the config file.
+func Load(file string) (*Config, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, errors.New(file + ":" + err.Error())
+	}
+	if c.File, err = filepath.Abs(file); err != nil {
+		return nil, err
+	}
+	c.Output.JUnit = c.abs(c.Output.JUnit)
+	c.Output.HTML = c.abs(c.Output.HTML)
+	c.Output.SARIF = c.abs(c.Output.SARIF)
+	c.Output.Checkstyle = c.abs(c.Output.Checkstyle)
+	c.Output.Cobertura = c.abs(c.Output.Cobertura)
+	c.Output.LCOV = c.abs(c.Output.LCOV)
+	c.Output.JSON = c.abs(c.Output.JSON)
+	if c.Output.Text != "-" {
+		c.Output.Text = c.abs(c.Output.Text)
+	}
+	c.History.Dir = c.abs(c.History.Dir)
+	c.Baseline = c.abs(c.Baseline)
+	return c, nil
+}
+
+// Parse parse the yaml config content.
+func Parse(data []byte) (*Config, error) {
+	c := Default()
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Dir return the dir of config file, it is the root dir of project.
+// return the working dir if the config is not loaded from file.
+func (c *Config) Dir() string {
+	if c.File != "" {
+		return filepath.Dir(c.File)
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+func (c *Config) abs(file string) string {
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(c.Dir(), file)
+}
+
+// Section read the options of the tool into v, v must be a pointer.
+// the field of v keep its value if it is not set in config,so v can be set to default before.
+func (c *Config) Section(name string, v interface{}) error {
+	section, ok := c.Sections[name]
+	if !ok || section == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(section)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return errors.New("config section " + name + ":" + err.Error())
+	}
+	return nil
+}
+
+// Match report whether the dir is matched by include globs and is not matched by exclude globs.
+func (c *Config) Match(dir string) bool {
+	if len(c.Include) == 0 && len(c.Exclude) == 0 {
+		return true
+	}
+	name := dir
+	if filepath.IsAbs(dir) {
+		rel, err := filepath.Rel(c.Dir(), dir)
+		if err != nil {
+			return true
+		}
+		name = rel
+	}
+	name = filepath.ToSlash(name)
+	for _, p := range c.Exclude {
+		if matchGlob(p, name) {
+			return false
+		}
+	}
+	if len(c.Include) == 0 {
+		return true
+	}
+	for _, p := range c.Include {
+		if matchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob report whether the slash separated name matches the pattern,
+// the pattern is same as path.Match and add "**" to match zero or more dirs,
+// the "..." is same as "**" like the go package pattern.
+func matchGlob(pattern, name string) bool {
+	pattern = strings.TrimPrefix(path.Clean(filepath.ToSlash(pattern)), "./")
+	pattern = strings.Replace(pattern, "...", "**", -1)
+	name = strings.TrimPrefix(path.Clean(name), "./")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	// the root dir "."
+	if len(name) == 1 && name[0] == "." {
+		name = nil
+	}
+	for len(pattern) > 0 {
+		if pattern[0] == "." {
+			pattern = pattern[1:]
+			continue
+		}
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testConfig = `
+tools: [gtest, gfmt]
+include: ["./..."]
+exclude: ["vendor/**", "**/testdata"]
+output:
+  junit: out/junit.xml
+parallel: 4
+tool_parallel:
+  gtest: 2
+gtest:
+  tags: [integration]
+  timeout: 30s
+  race: true
+gfmt:
+  simplify: false
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Tools, []string{"gtest", "gfmt"}) {
+		t.Fatalf("want tools gtest,gfmt, got %v", c.Tools)
+	}
+	if c.Output.JUnit != "out/junit.xml" {
+		t.Fatalf("want junit output out/junit.xml, got %q", c.Output.JUnit)
+	}
+	if c.Parallel != 4 || c.ToolParallel["gtest"] != 2 {
+		t.Fatalf("want parallel 4 and gtest 2, got %d %v", c.Parallel, c.ToolParallel)
+	}
+
+	var gtest struct {
+		Tags    []string `yaml:"tags"`
+		Timeout string   `yaml:"timeout"`
+		Race    bool     `yaml:"race"`
+		Cover   bool     `yaml:"cover"`
+	}
+	gtest.Cover = true
+	if err := c.Section("gtest", &gtest); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gtest.Tags, []string{"integration"}) || gtest.Timeout != "30s" || !gtest.Race {
+		t.Fatalf("want read gtest section, got %+v", gtest)
+	}
+	if !gtest.Cover {
+		t.Fatal("want keep the default value if not set in config")
+	}
+
+	// the section not in config
+	var glint struct {
+		MinConfidence float64 `yaml:"min_confidence"`
+	}
+	glint.MinConfidence = 0.8
+	if err := c.Section("glint", &glint); err != nil || glint.MinConfidence != 0.8 {
+		t.Fatalf("want keep default value, got %v,%v", glint.MinConfidence, err)
+	}
+
+	if _, err := Parse([]byte("tools: {")); err == nil {
+		t.Fatal("want error for bad yaml")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	c, err := Parse([]byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]bool{
+		".":                 true,
+		"gtest":             true,
+		"gtest/testdata":    false,
+		"vendor/a/b":        false,
+		"a/vendor":          true,
+		"reporter/formater": true,
+	}
+	for dir, want := range cases {
+		if got := c.Match(dir); got != want {
+			t.Fatalf("match %q want %v, got %v", dir, want, got)
+		}
+	}
+
+	c.Include = []string{"gtest/..."}
+	if c.Match("gfmt") || !c.Match("gtest") {
+		t.Fatal("want only match gtest dir")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root, err := ioutil.TempDir("", "gcodesharp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Discover(sub)
+	if err != nil || c.File != "" {
+		t.Fatalf("want default config if not found config file, got %q,%v", c.File, err)
+	}
+
+	file := filepath.Join(root, FileName)
+	if err := ioutil.WriteFile(file, []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err = Discover(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.File != file {
+		t.Fatalf("want found config file %s, got %s", file, c.File)
+	}
+	if want := filepath.Join(root, "out", "junit.xml"); c.Output.JUnit != want {
+		t.Fatalf("want output relative to config dir %s, got %s", want, c.Output.JUnit)
+	}
+	if !c.Match(filepath.Join(root, "a")) || c.Match(filepath.Join(root, "a", "testdata")) {
+		t.Fatal("want match the absolute dir relative to config dir")
+	}
+}
@@ -17,7 +17,7 @@ package context
 
 import (
 	"errors"
-	"go/build"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -29,9 +29,16 @@ type Context struct {
 	GopathList []string // List of GOPATHs in environment. Includes "src" dir.
 	Goroot     string   // The path to the standard library.
 	GoEnv      map[string]string
+	GoVersion  string // The version of go toolchain, e.g: go1.9.2
+
+	// Module is the main module of current dir, nil if it is not in module mode.
+	Module *Module
 
 	// Packages is list of need handle package
-	Packages []*build.Package
+	Packages []*Package
+
+	// Changes is the changed files of diff-aware mode, nil if all files are checked.
+	Changes Changes
 }
 
 // New create a new context.
@@ -48,9 +55,18 @@ func New() (*Context, error) {
 	if _, err := os.Stat(goroot); err != nil {
 		return nil, err
 	}
+	// GOMOD is the go.mod path of main module, it is empty or os.DevNull if not in module mode.
+	var module *Module
+	if gomod := env["GOMOD"]; gomod != "" && gomod != os.DevNull {
+		if module, err = LoadModule(filepath.Dir(gomod)); err != nil {
+			return nil, err
+		}
+	}
+
 	all := env["GOPATH"]
 	// Get the GOPATHs. Prepend the GOROOT to the list.
-	if len(all) == 0 {
+	// the GOPATH is not required in module mode.
+	if len(all) == 0 && module == nil {
 		return nil, errors.New("Missing GOPATH. Check your environment variable GOPATH")
 	}
 	gopathList := filepath.SplitList(all)
@@ -60,19 +76,47 @@ func New() (*Context, error) {
 		srcPath := filepath.Join(gopath, "src") + string(filepath.Separator)
 		srcPathEvaled, err := filepath.EvalSymlinks(srcPath)
 		if err != nil {
+			if module != nil && os.IsNotExist(err) {
+				// the GOPATH/src may not exist in module mode
+				continue
+			}
 			return nil, err
 		}
 		gopathGoroot = append(gopathGoroot, srcPath, srcPathEvaled+string(filepath.Separator))
 	}
 
+	goVersion := env["GOVERSION"]
+	if goVersion == "" {
+		// the GOVERSION is added to go env since go1.16
+		if goVersion, err = getGoVersion(); err != nil {
+			return nil, err
+		}
+	}
+
 	ctx := &Context{
 		GopathList: gopathGoroot,
 		Goroot:     goroot,
 		GoEnv:      env,
+		GoVersion:  goVersion,
+		Module:     module,
 	}
 	return ctx, nil
 }
 
+// getGoVersion get go version from the output of "go version",
+// e.g: go version go1.9.2 darwin/amd64
+func getGoVersion() (string, error) {
+	output, err := exec.Command("go", "version").CombinedOutput()
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) < 3 {
+		return "", fmt.Errorf("unable to determine go version from %q", output)
+	}
+	return fields[2], nil
+}
+
 func getGoEnv() (map[string]string, error) {
 	env := map[string]string{}
 	cmd := exec.Command("go", "env")
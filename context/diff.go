@@ -0,0 +1,212 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LineRange is a range of changed lines, the start and end are included.
+type LineRange struct {
+	Start, End int
+}
+
+// allLines is the range of a whole file, e.g: a new file.
+var allLines = LineRange{1, math.MaxInt32}
+
+// Changes is the changed files since a git ref,
+// the key is the absolute file path and the value is the changed line ranges of the file.
+// the path is compared after the symbolic links are resolved, see realPath.
+type Changes map[string][]LineRange
+
+// File report whether the file is changed.
+func (c Changes) File(file string) bool {
+	_, ok := c[realPath(file)]
+	return ok
+}
+
+// Line report whether the line of file is changed.
+func (c Changes) Line(file string, line int) bool {
+	for _, r := range c[realPath(file)] {
+		if line >= r.Start && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Dir report whether any file in the dir is changed, the file in sub dir is not included.
+func (c Changes) Dir(dir string) bool {
+	dir = realPath(dir)
+	for file := range c {
+		if filepath.Dir(file) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// GitChanges return the changed files since the git ref of the git repository which the dir in,
+// contains the committed, staged, unstaged and untracked changes, the deleted file is not included.
+func GitChanges(dir, ref string) (Changes, error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	// git resolves the symbolic links of top-level dir, but the package dir may be not, e.g: /tmp on darwin.
+	root = realPath(root)
+	if _, err := git(root, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("invalid git ref %q", ref)
+	}
+	diff, err := git(root, "diff", "--no-color", "--no-ext-diff", "--unified=0", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	changes := parseDiff(root, diff)
+	untracked, err := git(root, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range strings.Split(untracked, "\n") {
+		if file != "" {
+			changes[filepath.Join(root, filepath.FromSlash(file))] = []LineRange{allLines}
+		}
+	}
+	// the changed file may be a symbolic link too.
+	resolved := Changes{}
+	for file, lines := range changes {
+		resolved[realPath(file)] = lines
+	}
+	return resolved, nil
+}
+
+// realPath return the clean path with symbolic links resolved,
+// the path is only cleaned if it can not be resolved, e.g: the file does not exist.
+func realPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// regHunkHead match the hunk head of unified diff, and capture the line range of new file, e.g:
+//
+//	@@ -18,6 +18,7 @@ func A() {
+//	@@ -1 +1 @@
+var regHunkHead = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// parseDiff parse the `git diff --unified=0` output, the file path is joined with root dir.
+func parseDiff(root, diff string) Changes {
+	changes := Changes{}
+	var file string
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			name := strings.TrimPrefix(line, "+++ ")
+			if name == "/dev/null" {
+				// the file is deleted
+				file = ""
+				continue
+			}
+			file = filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(name, "b/")))
+			changes[file] = nil
+		case file != "" && strings.HasPrefix(line, "@@ "):
+			matches := regHunkHead.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(matches[1])
+			count := 1
+			if matches[2] != "" {
+				count, _ = strconv.Atoi(matches[2])
+			}
+			if count == 0 {
+				// only lines are deleted, no line of new file is changed.
+				continue
+			}
+			changes[file] = append(changes[file], LineRange{start, start + count - 1})
+		}
+	}
+	return changes
+}
+
+// SetChanges enable the diff-aware mode, only the packages which have changed file
+// and the packages which import them are kept, so the tests of them are run.
+func (ctx *Context) SetChanges(changes Changes) {
+	ctx.Changes = changes
+	changed := map[string]bool{}
+	for _, p := range ctx.Packages {
+		if ctx.PackageChanged(p) {
+			changed[p.ImportPath] = true
+		}
+	}
+	imports := func(p *Package) bool {
+		for _, list := range [][]string{p.Deps, p.TestImports, p.XTestImports} {
+			for _, path := range list {
+				if changed[path] {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	var list []*Package
+	for _, p := range ctx.Packages {
+		if changed[p.ImportPath] || imports(p) {
+			list = append(list, p)
+		}
+	}
+	ctx.Packages = list
+}
+
+// Changed report whether the file need check, it is always true if not in diff-aware mode.
+func (ctx *Context) Changed(file string) bool {
+	return ctx.Changes == nil || ctx.Changes.File(file)
+}
+
+// ChangedLine report whether the problem at the line of file need report,
+// it is always true if not in diff-aware mode or the line is unknown.
+func (ctx *Context) ChangedLine(file string, line int) bool {
+	return ctx.Changes == nil || line <= 0 || ctx.Changes.Line(file, line)
+}
+
+// PackageChanged report whether the package has changed file, it is always true if not in diff-aware mode.
+// the package which can not be found is treated as changed, so its load error is reported.
+func (ctx *Context) PackageChanged(p *Package) bool {
+	return ctx.Changes == nil || p.Dir == "" || ctx.Changes.Dir(p.Dir)
+}
+
+// ChangedFiles return the changed files of list, it is the list self if not in diff-aware mode.
+func (ctx *Context) ChangedFiles(files []string) []string {
+	if ctx.Changes == nil {
+		return files
+	}
+	var list []string
+	for _, f := range files {
+		if ctx.Changes.File(f) {
+			list = append(list, f)
+		}
+	}
+	return list
+}
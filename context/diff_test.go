@@ -0,0 +1,158 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"go/build"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/a.go b/a.go
+index 1..2 100644
+--- a/a.go
++++ b/a.go
+@@ -3,0 +4,2 @@ func A() {
++	x := 1
++	x = x
+@@ -10 +12 @@ func B() {
+-	return
++	return nil
+@@ -20,3 +21,0 @@ func C() {
+-	a
+-	b
+-	c
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,3 +0,0 @@
+-package p
+diff --git a/p/new.go b/p/new.go
+new file mode 100644
+--- /dev/null
++++ b/p/new.go
+@@ -0,0 +1,3 @@
++package p
++
++func New() {}
+`
+	changes := parseDiff("/r", diff)
+	if len(changes) != 2 || changes.File("/r/old.go") {
+		t.Fatalf("want 2 changed files without the deleted file, got %v", changes)
+	}
+	for _, line := range []int{4, 5, 12} {
+		if !changes.Line("/r/a.go", line) {
+			t.Fatalf("want a.go line %d changed, got %v", line, changes["/r/a.go"])
+		}
+	}
+	for _, line := range []int{3, 6, 11, 21} {
+		if changes.Line("/r/a.go", line) {
+			t.Fatalf("want a.go line %d not changed, got %v", line, changes["/r/a.go"])
+		}
+	}
+	if !changes.Line("/r/p/new.go", 3) || !changes.Dir("/r/p") || changes.Dir("/") {
+		t.Fatalf("want the new file changed, got %v", changes["/r/p/new.go"])
+	}
+}
+
+func TestGitChanges_Symlink(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	tmp, err := ioutil.TempDir("", "gcodesharp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	repo := filepath.Join(tmp, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, "p"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(repo, "p", "a.go"), []byte("package p\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if _, err := git(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(repo, "p", "a.go"), []byte("package p\n\nvar A = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// the working dir is a symbolic link of the repository.
+	link := filepath.Join(tmp, "link")
+	if err := os.Symlink(repo, link); err != nil {
+		t.Skip("symbolic link is not supported:", err)
+	}
+
+	changes, err := GitChanges(link, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(link, "p", "a.go")
+	if !changes.File(file) || !changes.Line(file, 3) || changes.Line(file, 1) || !changes.Dir(filepath.Join(link, "p")) {
+		t.Fatalf("want the changes found by the symbolic link path, got %v", changes)
+	}
+	if !changes.File(filepath.Join(repo, "p", "a.go")) {
+		t.Fatalf("want the changes found by the real path, got %v", changes)
+	}
+}
+
+func TestSetChanges(t *testing.T) {
+	pkg := func(path, dir string, deps ...string) *Package {
+		return &Package{Package: &build.Package{ImportPath: path, Dir: dir}, Deps: deps}
+	}
+	ctx := &Context{Packages: []*Package{
+		pkg("m/a", "/m/a"),
+		pkg("m/b", "/m/b", "m/a"),
+		pkg("m/c", "/m/c", "fmt"),
+		pkg("m/d", "/m/d"),
+	}}
+	// m/d test m/c
+	ctx.Packages[3].XTestImports = []string{"m/c"}
+	if !ctx.Changed("/m/c/c.go") || !ctx.ChangedLine("/m/c/c.go", 1) {
+		t.Fatal("want all files changed if not in diff-aware mode")
+	}
+
+	ctx.SetChanges(Changes{"/m/a/a.go": {{1, 2}}, "/m/c/c_test.go": {{5, 5}}})
+	var got []string
+	for _, p := range ctx.Packages {
+		got = append(got, p.ImportPath)
+	}
+	if len(got) != 4 {
+		t.Fatalf("want the changed packages and their reverse dependencies, got %v", got)
+	}
+	ctx.Packages = ctx.Packages[:1]
+	ctx.SetChanges(Changes{"/m/b/b.go": nil})
+	if len(ctx.Packages) != 0 {
+		t.Fatalf("want the package which does not import the changed package is removed, got %d", len(ctx.Packages))
+	}
+	if files := ctx.ChangedFiles([]string{"/m/a/a.go", "/m/b/b.go"}); len(files) != 1 || files[0] != "/m/b/b.go" {
+		t.Fatalf("want only changed files, got %v", files)
+	}
+	if !ctx.ChangedLine("/m/a/a.go", 0) || ctx.ChangedLine("/m/b/b.go", 1) {
+		t.Fatal("want the unknown line reported and the unchanged line not reported")
+	}
+}
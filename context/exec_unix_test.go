@@ -0,0 +1,54 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !windows
+// +build !windows
+
+package context
+
+import (
+	"bytes"
+	gocontext "context"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestRunCommand(t *testing.T) {
+	var stdout bytes.Buffer
+	// the sleep is the child process of sh, it keeps the stdout open if it is not killed.
+	cmd := exec.Command("sh", "-c", "echo started; sleep 30 & wait")
+	cmd.Stdout = &stdout
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := RunCommand(ctx, cmd); err == nil {
+		t.Fatal("want error for killed command")
+	}
+	if cost := time.Since(start); cost > 10*time.Second {
+		t.Fatalf("want the child process killed, but the command run %s", cost)
+	}
+	if stdout.String() != "started\n" {
+		t.Fatalf("want the output before killed, got %q", stdout.String())
+	}
+
+	if err := RunCommand(ctx, exec.Command("true")); err != gocontext.DeadlineExceeded {
+		t.Fatalf("want the command not started if ctx is done, got %v", err)
+	}
+	if err := RunCommand(gocontext.Background(), exec.Command("true")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Module is the main module of go modules project.
+type Module struct {
+	Path      string // module path, e.g: github.com/ysqi/gcodesharp
+	Dir       string // directory holding go.mod
+	GoMod     string // path to go.mod file
+	GoVersion string // go version of the go directive, e.g: 1.12
+}
+
+// LoadModule find the go.mod from dir and its parent dir, and parse it.
+// return nil if not found go.mod.
+func LoadModule(dir string) (*Module, error) {
+	gomod := findGoMod(dir)
+	if gomod == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(gomod)
+	if err != nil {
+		return nil, err
+	}
+	m, err := parseGoMod(data)
+	if err != nil {
+		return nil, errors.New(gomod + ":" + err.Error())
+	}
+	m.GoMod = gomod
+	m.Dir = filepath.Dir(gomod)
+	return m, nil
+}
+
+// findGoMod walk up from dir to find the go.mod file.
+func findGoMod(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		gomod := filepath.Join(dir, "go.mod")
+		if fi, err := os.Stat(gomod); err == nil && !fi.IsDir() {
+			return gomod
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// parseGoMod parse the module path and go version from go.mod content.
+func parseGoMod(data []byte) (*Module, error) {
+	m := &Module{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "module":
+			m.Path = unquote(fields[1])
+		case "go":
+			m.GoVersion = fields[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if m.Path == "" {
+		return nil, errors.New("no module declaration in go.mod")
+	}
+	return m, nil
+}
+
+func unquote(s string) string {
+	if un, err := strconv.Unquote(s); err == nil {
+		return un
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	data := []byte(`// the main module
+module "github.com/ysqi/gcodesharp" // quoted
+
+go 1.12
+
+require (
+	github.com/spf13/cobra v0.0.1
+)
+`)
+	m, err := parseGoMod(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Path != "github.com/ysqi/gcodesharp" {
+		t.Fatalf("want module path %q, got %q", "github.com/ysqi/gcodesharp", m.Path)
+	}
+	if m.GoVersion != "1.12" {
+		t.Fatalf("want go version %q, got %q", "1.12", m.GoVersion)
+	}
+
+	if _, err := parseGoMod([]byte("go 1.12\n")); err == nil {
+		t.Fatal("want error if missing module declaration")
+	}
+}
+
+func TestLoadModule(t *testing.T) {
+	root, err := ioutil.TempDir("", "gcodesharp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	// the symbolic link is used on darwin temp dir.
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := LoadModule(sub); err != nil || m != nil {
+		t.Fatalf("want nil module without go.mod, got %+v,%v", m, err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/m\n\ngo 1.13\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadModule(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil || m.Dir != root || m.Path != "example.com/m" || m.GoVersion != "1.13" {
+		t.Fatalf("want module example.com/m in %s, got %+v", root, m)
+	}
+
+	ctx := &Context{Module: m}
+	importPath, _, err := ctx.FindImportPath(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if importPath != "example.com/m/a/b" {
+		t.Fatalf("want import path %q, got %q", "example.com/m/a/b", importPath)
+	}
+	if importPath, _, err = ctx.FindImportPath(root); err != nil || importPath != "example.com/m" {
+		t.Fatalf("want import path %q, got %q,%v", "example.com/m", importPath, err)
+	}
+}
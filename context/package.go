@@ -0,0 +1,79 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"bytes"
+	"go/build"
+	"path/filepath"
+	"strings"
+)
+
+// PackageError is the error of loading package, it is reported by `go list -e`.
+type PackageError struct {
+	ImportStack []string // shortest path from package named on command line to this one
+	Pos         string   // position of error (if present, file:line:col)
+	Err         string   // the error itself
+}
+
+func (e *PackageError) Error() string {
+	if e.Pos != "" {
+		return e.Pos + ": " + e.Err
+	}
+	return e.Err
+}
+
+// Package is a go package found by `go list -json -e`,
+// contains the package info of go/build and the load errors.
+type Package struct {
+	*build.Package
+
+	EmbedFiles []string // files matched by //go:embed patterns
+	Deps       []string // all (recursively) imported dependencies
+	Incomplete bool     // this package or a dependency has an error
+
+	Error      *PackageError   // error loading package
+	DepsErrors []*PackageError // errors loading dependencies
+}
+
+// HasLoadError report whether the package or its dependencies can not be loaded.
+func (p *Package) HasLoadError() bool {
+	return p.Error != nil || len(p.DepsErrors) > 0
+}
+
+// LoadError return all load errors of package and its dependencies as a text,
+// each error is a line. return empty if no error.
+func (p *Package) LoadError() string {
+	var buf bytes.Buffer
+	if p.Error != nil {
+		buf.WriteString(p.Error.Error())
+		buf.WriteString("\n")
+	}
+	for _, e := range p.DepsErrors {
+		buf.WriteString(e.Error())
+		buf.WriteString("\n")
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// absPos make the file of error position be a absolute path,
+// the go list print the position relative to the working dir.
+func (e *PackageError) absPos(dir string) {
+	if e == nil || e.Pos == "" || filepath.IsAbs(e.Pos) {
+		return
+	}
+	e.Pos = filepath.Join(dir, e.Pos)
+}
@@ -16,8 +16,11 @@
 package context
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"go/build"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -53,30 +56,71 @@ func GofmtPath() string {
 
 // GetPackagePaths get all import path prefixed with input
 func GetPackagePaths(pkgpath string) ([]string, error) {
-	//if pkgpath == "" {
-	//	pkgpath = "./..."
-	//} else if pkgpath == "." {
-	//	pkgpath = "./..."
-	//} else {
-	//	pkgpath += "..."
-	//}
 	list := []string{}
-	cmd := exec.Command("go", "list", pkgpath)
-	output, err := cmd.CombinedOutput()
+	pkgs, err := ListPackages("", pkgpath)
 	if err != nil {
 		return list, err
 	}
-	outputStr := string(output)
-	// e.g warning:,
-	if strings.Contains(outputStr, ":") {
-		return list, errors.New(outputStr)
+	for _, p := range pkgs {
+		list = append(list, p.ImportPath)
 	}
-	for _, line := range strings.Split(outputStr, "\n") {
-		// ignore vendor path ,empty path.
-		if line == "" || strings.Contains(line, "/vendor/") {
+	return list, nil
+}
+
+// ListPackages find packages by `go list -json -e` in dir,
+// the pattern can be a import path or a relative dir, e.g: ./... , github.com/ysqi/com/...
+// the go list works in module mode and GOPATH mode. the current dir is used if dir is empty.
+// the package which can not be loaded is also returned, its load error is in the Error and DepsErrors.
+func ListPackages(dir string, patterns ...string) ([]*Package, error) {
+	args := []string{"list", "-json", "-e"}
+	for _, p := range patterns {
+		// the empty pattern means current dir
+		if p == "" {
+			p = "."
+		}
+		args = append(args, p)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil && len(output) == 0 {
+		if stderr.Len() > 0 {
+			return nil, errors.New(strings.TrimSpace(stderr.String()))
+		}
+		return nil, err
+	}
+
+	workdir := dir
+	if workdir == "" {
+		if workdir, err = os.Getwd(); err != nil {
+			return nil, err
+		}
+	}
+	workdir, err = filepath.Abs(workdir)
+	if err != nil {
+		return nil, err
+	}
+	var list []*Package
+	dec := json.NewDecoder(bytes.NewReader(output))
+	for dec.More() {
+		p := &Package{}
+		if err := dec.Decode(p); err != nil {
+			return nil, fmt.Errorf("decode go list output:%s", err)
+		}
+		if p.Package == nil {
+			p.Package = &build.Package{}
+		}
+		// ignore vendor path
+		if strings.Contains(p.ImportPath, "/vendor/") {
 			continue
 		}
-		list = append(list, line)
+		p.Error.absPos(workdir)
+		for _, e := range p.DepsErrors {
+			e.absPos(workdir)
+		}
+		list = append(list, p)
 	}
 	return list, nil
 }
@@ -97,6 +141,19 @@ func (ctx *Context) FindImportPath(dir string) (importPath, gopath string, err e
 		dirs = append(dirs, dirResolved)
 	}
 
+	// the package in main module
+	if m := ctx.Module; m != nil {
+		for _, dir := range dirs {
+			if fileStringEquals(dir, m.Dir) {
+				return m.Path, "", nil
+			}
+			if fileHasPrefix(dir, m.Dir+string(filepath.Separator)) {
+				importPath = m.Path + "/" + slashToImportPath(fileTrimPrefix(dir, m.Dir+string(filepath.Separator)))
+				return importPath, "", nil
+			}
+		}
+	}
+
 	for _, gopath := range ctx.GopathList {
 		for _, dir := range dirs {
 			if fileHasPrefix(dir, gopath) || fileStringEquals(dir, gopath) {
@@ -107,7 +164,7 @@ func (ctx *Context) FindImportPath(dir string) (importPath, gopath string, err e
 		}
 	}
 
-	return "", "", fmt.Errorf("Dir %q not a go package or not in GOPATH", dir)
+	return "", "", fmt.Errorf("Dir %q not a go package or not in GOPATH and main module", dir)
 }
 
 func slashToImportPath(path string) string {
@@ -181,9 +238,16 @@ func parseGoEnvLine(line string) (key, value string, ok bool) {
 		return "", "", false
 	}
 
-	un, err := strconv.Unquote(parts[1])
+	value = parts[1]
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		// unix shell quoted since go1.17, e.g: GOROOT='/usr/local/go',
+		// and the single quote is escaped as '\''
+		return parts[0], strings.Replace(value[1:len(value)-1], `'\''`, `'`, -1), true
+	}
+
+	un, err := strconv.Unquote(value)
 	if err != nil {
-		return parts[0], parts[1], true
+		return parts[0], value, true
 	}
 	return parts[0], un, true
 }
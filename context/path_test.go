@@ -56,3 +56,50 @@ func contains(list []string, item string) bool {
 	}
 	return false
 }
+
+func TestParseGoEnvLine(t *testing.T) {
+	cases := []struct {
+		line, key, value string
+	}{
+		{`GOROOT="/usr/local/go"`, "GOROOT", "/usr/local/go"},
+		{`GOROOT='/usr/local/go'`, "GOROOT", "/usr/local/go"},
+		{`CGO_CFLAGS='-O2 -g'`, "CGO_CFLAGS", "-O2 -g"},
+		{`GOFLAGS='-ldflags=-X=a'\''b'`, "GOFLAGS", "-ldflags=-X=a'b"},
+		{`set GOPATH=C:\go`, "GOPATH", `C:\go`},
+	}
+	for _, c := range cases {
+		key, value, ok := parseGoEnvLine(c.line)
+		if !ok || key != c.key || value != c.value {
+			t.Fatalf("parse %q want %s=%s, got %s=%s", c.line, c.key, c.value, key, value)
+		}
+	}
+}
+
+func TestListPackages(t *testing.T) {
+	list, err := ListPackages("", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Name != "context" {
+		t.Fatalf("want get context package, got %+v", list)
+	}
+	if !contains(list[0].GoFiles, "context.go") || !contains(list[0].TestGoFiles, "path_test.go") {
+		t.Fatalf("want package files, got %v and %v", list[0].GoFiles, list[0].TestGoFiles)
+	}
+	if !contains(list[0].Deps, "go/build") || list[0].HasLoadError() {
+		t.Fatalf("want deps contains go/build and no load error, got %v,%q", list[0].Deps, list[0].LoadError())
+	}
+}
+
+func TestListPackagesWithError(t *testing.T) {
+	list, err := ListPackages("", "./notexist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || !list[0].HasLoadError() {
+		t.Fatalf("want get a package with load error, got %+v", list)
+	}
+	if list[0].LoadError() == "" {
+		t.Fatal("want load error message, got empty")
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package coverage
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// Func is the coverage of a function.
+type Func struct {
+	Name      string // the function name, the method name look like: (*T).Name
+	StartLine int
+	EndLine   int
+	NumStmt   int
+	Covered   int
+	// Hits is the executed count of the function, it is the count of the first block.
+	Hits  int
+	Lines []LineHit
+}
+
+// Percent return the percentage of covered statements, zero if no statement.
+func (f *Func) Percent() float64 {
+	return percent(f.NumStmt, f.Covered)
+}
+
+// Funcs parse the go file of profile and return the coverage of each function.
+// return error if the file can not be parsed.
+func (p *Profile) Funcs() ([]*Func, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, p.Path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	var list []*Func
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+		f := &Func{
+			Name:      funcName(fn),
+			StartLine: start.Line,
+			EndLine:   end.Line,
+		}
+		first := true
+		for _, b := range p.Blocks {
+			// the block in function body
+			if b.StartLine < start.Line || (b.StartLine == start.Line && b.StartCol < start.Column) {
+				continue
+			}
+			if b.EndLine > end.Line || (b.EndLine == end.Line && b.EndCol > end.Column) {
+				continue
+			}
+			if first {
+				f.Hits = b.Count
+				first = false
+			}
+			f.NumStmt += b.NumStmt
+			if b.Count > 0 {
+				f.Covered += b.NumStmt
+			}
+		}
+		f.Lines = lineHits(p.Blocks, f.StartLine, f.EndLine)
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+// funcName return the name of function, e.g: Foo, T.Foo, (*T).Foo
+func funcName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	typ := fn.Recv.List[0].Type
+	// the generic receiver, e.g: T[K]
+	if index, ok := typ.(*ast.IndexExpr); ok {
+		typ = index.X
+	}
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		if id, ok := t.X.(*ast.Ident); ok {
+			return "(*" + id.Name + ")." + fn.Name.Name
+		}
+		if index, ok := t.X.(*ast.IndexExpr); ok {
+			if id, ok := index.X.(*ast.Ident); ok {
+				return "(*" + id.Name + ")." + fn.Name.Name
+			}
+		}
+	case *ast.Ident:
+		return t.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
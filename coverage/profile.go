@@ -0,0 +1,239 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package coverage parse and merge the go test cover profiles,
+// and compute the coverage of each line and function.
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Profile is the cover profile of a go file.
+type Profile struct {
+	// FileName is the file name in cover profile, e.g: github.com/ysqi/gcodesharp/main.go
+	FileName string
+	// Path is the file path on disk, empty if it can not be resolved.
+	Path   string
+	Mode   string // set, count or atomic
+	Blocks []Block
+}
+
+// Block is a code block of cover profile.
+type Block struct {
+	StartLine int
+	StartCol  int
+	EndLine   int
+	EndCol    int
+	NumStmt   int
+	Count     int
+}
+
+// LineHit is the executed count of a line.
+type LineHit struct {
+	Line int
+	Hits int
+}
+
+// regBlock match the block line of cover profile, e.g:
+//
+//	github.com/ysqi/com/a.go:10.30,12.2 1 3
+var regBlock = regexp.MustCompile(`^(.+):(\d+)\.(\d+),(\d+)\.(\d+) (\d+) (\d+)$`)
+
+// ParseProfiles parse the cover profile which is created by go test -coverprofile.
+// the profiles are sorted by file name.
+func ParseProfiles(r io.Reader) ([]*Profile, error) {
+	files := map[string]*Profile{}
+	mode := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "mode: ") {
+			// the merged profile may contains more than one mode line.
+			mode = strings.TrimPrefix(line, "mode: ")
+			continue
+		}
+		matches := regBlock.FindStringSubmatch(line)
+		if matches == nil {
+			return nil, fmt.Errorf("line %q does not match cover profile format", line)
+		}
+		p := files[matches[1]]
+		if p == nil {
+			p = &Profile{FileName: matches[1], Mode: mode}
+			files[matches[1]] = p
+		}
+		p.Blocks = append(p.Blocks, Block{
+			StartLine: atoi(matches[2]),
+			StartCol:  atoi(matches[3]),
+			EndLine:   atoi(matches[4]),
+			EndCol:    atoi(matches[5]),
+			NumStmt:   atoi(matches[6]),
+			Count:     atoi(matches[7]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	list := make([]*Profile, 0, len(files))
+	for _, p := range files {
+		list = append(list, p)
+	}
+	return Merge(list), nil
+}
+
+// Merge merge the profiles of same file,
+// the count of same block is added in count mode, or is the max count in set mode.
+// the result is sorted by file name, and the blocks are sorted by position.
+func Merge(profiles ...[]*Profile) []*Profile {
+	files := map[string]*Profile{}
+	var names []string
+	for _, list := range profiles {
+		for _, p := range list {
+			dst := files[p.FileName]
+			if dst == nil {
+				dst = &Profile{FileName: p.FileName, Path: p.Path, Mode: p.Mode}
+				files[p.FileName] = dst
+				names = append(names, p.FileName)
+			}
+			if dst.Path == "" {
+				dst.Path = p.Path
+			}
+			dst.Blocks = mergeBlocks(dst.Mode, dst.Blocks, p.Blocks)
+		}
+	}
+	sort.Strings(names)
+	result := make([]*Profile, 0, len(names))
+	for _, name := range names {
+		result = append(result, files[name])
+	}
+	return result
+}
+
+func mergeBlocks(mode string, dst, src []Block) []Block {
+	index := make(map[[4]int]int, len(dst))
+	for i, b := range dst {
+		index[b.pos()] = i
+	}
+	for _, b := range src {
+		i, ok := index[b.pos()]
+		if !ok {
+			index[b.pos()] = len(dst)
+			dst = append(dst, b)
+			continue
+		}
+		if mode == "set" {
+			if b.Count > dst[i].Count {
+				dst[i].Count = b.Count
+			}
+		} else {
+			dst[i].Count += b.Count
+		}
+	}
+	sort.Slice(dst, func(i, j int) bool {
+		a, b := dst[i], dst[j]
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.StartCol < b.StartCol
+	})
+	return dst
+}
+
+func (b Block) pos() [4]int {
+	return [4]int{b.StartLine, b.StartCol, b.EndLine, b.EndCol}
+}
+
+// Statements return the number of statements and covered statements.
+func (p *Profile) Statements() (total, covered int) {
+	for _, b := range p.Blocks {
+		total += b.NumStmt
+		if b.Count > 0 {
+			covered += b.NumStmt
+		}
+	}
+	return
+}
+
+// Percent return the percentage of covered statements, zero if no statement.
+func (p *Profile) Percent() float64 {
+	return percent(p.Statements())
+}
+
+// Lines return the executed count of each line which has statement, sorted by line.
+// the line in more than one block use the max count, because the block end line
+// is the start line of next block.
+func (p *Profile) Lines() []LineHit {
+	return lineHits(p.Blocks, 0, 0)
+}
+
+func lineHits(blocks []Block, start, end int) []LineHit {
+	hits := map[int]int{}
+	for _, b := range blocks {
+		if b.NumStmt == 0 {
+			continue
+		}
+		for line := b.StartLine; line <= b.EndLine; line++ {
+			if (start > 0 && line < start) || (end > 0 && line > end) {
+				continue
+			}
+			if c, ok := hits[line]; !ok || b.Count > c {
+				hits[line] = b.Count
+			}
+		}
+	}
+	list := make([]LineHit, 0, len(hits))
+	for line, count := range hits {
+		list = append(list, LineHit{Line: line, Hits: count})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Line < list[j].Line })
+	return list
+}
+
+// Total return the statements and covered statements of all profiles.
+func Total(profiles []*Profile) (total, covered int) {
+	for _, p := range profiles {
+		t, c := p.Statements()
+		total += t
+		covered += c
+	}
+	return
+}
+
+// TotalPercent return the percentage of covered statements of all profiles, zero if no statement.
+// the profiles of same file should be merged before, or the statements are counted repeatedly.
+func TotalPercent(profiles []*Profile) float64 {
+	return percent(Total(profiles))
+}
+
+func percent(total, covered int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100
+}
+
+func atoi(s string) int {
+	v, _ := strconv.Atoi(s)
+	return v
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package coverage
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func load(t *testing.T, file string) []*Profile {
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	profiles, err := ParseProfiles(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range profiles {
+		p.Path = "./testdata/sample.go"
+	}
+	return profiles
+}
+
+func TestParseProfiles(t *testing.T) {
+	profiles := load(t, "./testdata/sample.cover")
+	if len(profiles) != 1 {
+		t.Fatalf("want 1 profile, got %d", len(profiles))
+	}
+	p := profiles[0]
+	if p.FileName != "example.com/sample/sample.go" || p.Mode != "count" || len(p.Blocks) != 6 {
+		t.Fatalf("want 6 blocks of sample.go, got %+v", p)
+	}
+	if total, covered := p.Statements(); total != 6 || covered != 5 {
+		t.Fatalf("want 5 of 6 statements covered, got %d of %d", covered, total)
+	}
+	lines := p.Lines()
+	// the line 22 is the end of first block and start of second block,use the max count
+	want := map[int]int{21: 2, 22: 2, 23: 0, 24: 0, 25: 2, 29: 3, 33: 2}
+	for _, l := range lines {
+		if hits, ok := want[l.Line]; ok && hits != l.Hits {
+			t.Fatalf("want line %d hits %d, got %d", l.Line, hits, l.Hits)
+		}
+	}
+
+	if _, err := ParseProfiles(strings.NewReader("bad line")); err == nil {
+		t.Fatal("want error for bad profile")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := load(t, "./testdata/sample.cover")
+	b := load(t, "./testdata/sample2.cover")
+	merged := Merge(a, b)
+	if len(merged) != 1 || len(merged[0].Blocks) != 6 {
+		t.Fatalf("want 6 blocks of merged profile, got %+v", merged)
+	}
+	// count mode add the count
+	if c := merged[0].Blocks[3].Count; c != 4 {
+		t.Fatalf("want count 4 after merged, got %d", c)
+	}
+	if merged[0].Path != "./testdata/sample.go" {
+		t.Fatalf("want keep the file path, got %q", merged[0].Path)
+	}
+	// 5 of 6 statements are covered after merged, not the average of 83.33% and 66.67%
+	if p := TotalPercent(merged); fmt.Sprintf("%.2f", p) != "83.33" {
+		t.Fatalf("want total percent 83.33, got %.2f", p)
+	}
+}
+
+func TestFuncs(t *testing.T) {
+	funcs, err := load(t, "./testdata/sample.cover")[0].Funcs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(funcs) != 2 {
+		t.Fatalf("want 2 funcs, got %d", len(funcs))
+	}
+	add, abs := funcs[0], funcs[1]
+	if add.Name != "(*T).Add" || add.StartLine != 21 || add.EndLine != 26 || add.Hits != 2 {
+		t.Fatalf("want (*T).Add at line 21-26 and hits 2, got %+v", add)
+	}
+	if add.NumStmt != 3 || add.Covered != 2 {
+		t.Fatalf("want 2 of 3 statements covered in Add, got %d of %d", add.Covered, add.NumStmt)
+	}
+	if abs.Name != "Abs" || abs.Percent() != 100 {
+		t.Fatalf("want Abs full covered, got %s %.2f", abs.Name, abs.Percent())
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gbuild
+
+import (
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// Name return the service name which other services depend on.
+func (s *Service) Name() string {
+	return "gbuild"
+}
+
+// Depends return nil, go build runs first.
+func (s *Service) Depends() []string {
+	return nil
+}
+
+// Produce put the build errors of packages which can not be compiled,
+// so the dependent services skip them and the compile error is reported once.
+func (s *Service) Produce(a *reporter.Artifacts) {
+	errs := reporter.BuildErrors{}
+	for _, p := range s.Report.Packages {
+		if p.Failed() {
+			errs[p.Name] = p.ErrorContent()
+		}
+	}
+	a.Put(reporter.ArtifactBuildErrors, errs)
+}
@@ -0,0 +1,52 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gbuild
+
+import (
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// ToolName the tool name of finding.
+const ToolName = "gobuild"
+
+// Tool return the tool name of findings.
+func (r *Report) Tool() string {
+	return ToolName
+}
+
+// Findings return a error finding for each compile error,
+// the error without position is reported to the package dir.
+func (r *Report) Findings() []reporter.Finding {
+	var list []reporter.Finding
+	for _, p := range r.Packages {
+		for _, e := range p.Errors {
+			file := e.File
+			if file == "" {
+				file = p.Dir
+			}
+			list = append(list, reporter.Finding{
+				Tool:     ToolName,
+				Rule:     "compile",
+				Severity: reporter.SeverityError,
+				File:     file,
+				Line:     e.Line,
+				Col:      e.Col,
+				Message:  e.Message,
+			})
+		}
+	}
+	return list
+}
@@ -0,0 +1,228 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package gbuild checks the packages can be compiled by go build,
+// the other services depend on it to skip the package which can not be compiled.
+package gbuild
+
+import (
+	"bytes"
+	gocontext "context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+type errHander func(fm string, args ...interface{})
+
+// Report go build result
+type Report struct {
+	Packages []*Package
+	ExecPath string
+	Created  time.Time
+	Cost     float32
+	Env      struct {
+		GoVersion string
+		OS        string
+		Arch      string
+	}
+	SysErr error `json:"-"` // the error of go build, see JError
+}
+
+// Error is a compile error.
+type Error struct {
+	File    string // empty if the error has not position
+	Line    int
+	Col     int
+	Message string
+}
+
+// Package a package compiled by go build
+type Package struct {
+	// Name import path
+	Name   string
+	Dir    string
+	Errors []Error
+}
+
+// Failed return true if the package can not be compiled.
+func (p *Package) Failed() bool {
+	return len(p.Errors) > 0
+}
+
+// ErrorContent return the compile errors in text, e.g: a.go:5:2: undefined: x
+func (p *Package) ErrorContent() string {
+	var lines []string
+	for _, e := range p.Errors {
+		if e.File == "" {
+			lines = append(lines, e.Message)
+			continue
+		}
+		lines = append(lines, e.File+":"+strconv.Itoa(e.Line)+":"+strconv.Itoa(e.Col)+": "+e.Message)
+	}
+	return strings.Join(lines, "\n")
+}
+
+type Service struct {
+	Report
+	// Scheduler runs the go build of each package, all of them run at once if nil.
+	Scheduler *reporter.Scheduler
+	// Events streams the progress of each package, the events are dropped if nil.
+	Events *reporter.EventBus
+
+	ctx  *context.Context
+	jobs *reporter.Jobs
+
+	errh errHander
+}
+
+func New(ctx *context.Context, errh errHander) (*Service, error) {
+	return &Service{
+		ctx:  ctx,
+		errh: errh,
+	}, nil
+}
+
+// Run go build and block until done, the go build processes are killed if ctx is done.
+func (s *Service) Run(ctx gocontext.Context) error {
+	s.Created = time.Now()
+	s.Env.GoVersion = runtime.Version()
+	s.Env.OS = runtime.GOOS
+	s.Env.Arch = runtime.GOARCH
+	s.ExecPath = "go build"
+
+	s.jobs = reporter.NewJobs(ctx, "gbuild", s.Scheduler, s.Events, s.errh)
+	ctx = s.jobs.Context()
+	results := make([]*Package, len(s.ctx.Packages))
+	for i, p := range s.ctx.Packages {
+		// the load error is reported by other services,
+		// and only the changed packages are checked in diff-aware mode.
+		if p.HasLoadError() || !s.ctx.PackageChanged(p) {
+			continue
+		}
+		// batch go build
+		i, p := i, p
+		s.jobs.Go(p.ImportPath, func() (bool, []reporter.Finding) {
+			pkg := s.gobuild(ctx, p)
+			if pkg == nil {
+				// killed
+				return false, nil
+			}
+			results[i] = pkg
+			return pkg.Failed(), (&Report{Packages: []*Package{pkg}}).Findings()
+		})
+	}
+	// wait for all go build done
+	s.SysErr = s.jobs.Wait()
+	for _, pkg := range results {
+		if pkg != nil {
+			s.Report.Packages = append(s.Report.Packages, pkg)
+		}
+	}
+	sort.SliceStable(s.Report.Packages, func(i, j int) bool {
+		return s.Report.Packages[i].Name < s.Report.Packages[j].Name
+	})
+	s.Cost = float32(time.Since(s.Created).Seconds())
+	return s.SysErr
+}
+
+// gobuild compile the package, return nil if it is killed.
+func (s *Service) gobuild(ctx gocontext.Context, p *context.Package) *Package {
+	pkg, err := runGoBuild(ctx, p.Dir, p.ImportPath, p.Name == "main")
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		s.jobs.Error(err.Error())
+		return nil
+	}
+	return pkg
+}
+
+// Match the compile error, e.g: ./bad.go:8:2: undefined: x
+var regLine = regexp.MustCompile(`^(.+\.go):(\d+):(?:(\d+):)?\s*(.*)$`)
+
+// runGoBuild compile the package in dir, the binary of main package is discarded.
+// the compile error is returned in package, the error is only returned if go build can not run.
+func runGoBuild(ctx gocontext.Context, dir, importPath string, isMain bool) (*Package, error) {
+	var output bytes.Buffer
+	cmd := exec.Command("go", "build")
+	if isMain {
+		cmd.Args = append(cmd.Args, "-o", os.DevNull)
+	}
+	cmd.Args = append(cmd.Args, importPath)
+	cmd.Dir = dir
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	waitErr := context.RunCommand(ctx, cmd)
+
+	pkg := &Package{Name: importPath, Dir: dir}
+	if waitErr == nil {
+		return pkg, nil
+	}
+	if _, ok := waitErr.(*exec.ExitError); !ok {
+		return nil, waitErr
+	}
+	pkg.Errors = parse(output.String(), dir)
+	return pkg, nil
+}
+
+// parse the go build output, the relative file name will join with dir.
+// the output which is not a compile error is returned as a error without position.
+func parse(output, dir string) []Error {
+	var (
+		list  []Error
+		other []string
+	)
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+		case strings.HasPrefix(line, "#"):
+			// package name, e.g: # github.com/ysqi/gcodesharp
+		case strings.HasPrefix(line, "\t"):
+			// the detail of last error, e.g: have (int) want (string)
+			if len(list) > 0 {
+				list[len(list)-1].Message += "\n" + strings.TrimSpace(line)
+			}
+		default:
+			matches := regLine.FindStringSubmatch(line)
+			if len(matches) != 5 {
+				other = append(other, line)
+				continue
+			}
+			file := matches[1]
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(dir, file)
+			}
+			e := Error{File: file, Message: matches[4]}
+			e.Line, _ = strconv.Atoi(matches[2])
+			e.Col, _ = strconv.Atoi(matches[3])
+			list = append(list, e)
+		}
+	}
+	if len(list) == 0 && len(other) > 0 {
+		list = append(list, Error{Message: strings.Join(other, "\n")})
+	}
+	return list
+}
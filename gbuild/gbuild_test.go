@@ -0,0 +1,107 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gbuild
+
+import (
+	gocontext "context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+func TestRun(t *testing.T) {
+	ctx, err := context.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err := context.ListPackages("", "./testdata/good", "./testdata/bad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.Packages = append(ctx.Packages, pkgs...)
+	s, err := New(ctx, func(fmt_ string, args ...interface{}) {
+		t.Fatalf(fmt_, args...)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Events = reporter.NewEventBus()
+	events := map[reporter.EventKind]int{}
+	s.Events.Subscribe(func(e reporter.Event) { events[e.Kind]++ })
+	if err := s.Run(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if events[reporter.EventPackageFinished] != 2 || events[reporter.EventFinding] != 1 {
+		t.Fatalf("want 2 packages finished and 1 finding, got %v", events)
+	}
+	if len(s.Report.Packages) != 2 {
+		t.Fatalf("want 2 packages, got %d", len(s.Report.Packages))
+	}
+	// sorted by name
+	bad, good := s.Report.Packages[0], s.Report.Packages[1]
+	if good.Failed() {
+		t.Fatalf("want good package compiled, got %s", good.ErrorContent())
+	}
+	if !bad.Failed() || filepath.Base(bad.Errors[0].File) != "bad.go" || bad.Errors[0].Line != 5 ||
+		!strings.Contains(bad.Errors[0].Message, "undefined: x") {
+		t.Fatalf("want undefined error at bad.go:5, got %+v", bad.Errors)
+	}
+
+	a := reporter.NewArtifacts()
+	s.Produce(a)
+	var errs reporter.BuildErrors
+	if err := a.Get(reporter.ArtifactBuildErrors, &errs); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[bad.Name], "undefined: x") {
+		t.Fatalf("want the build error of bad package, got %v", errs)
+	}
+
+	suites, err := s.ToJunit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := suites.Suites[0]
+	if ts.Tests != 2 || ts.Failures != 1 || ts.TestCases[0].Failure == nil ||
+		!strings.Contains(ts.TestCases[0].Failure.Contents, "undefined: x") {
+		t.Fatalf("want the compile error of bad package in junit, got %+v", ts)
+	}
+}
+
+func TestParse(t *testing.T) {
+	output := `# example.com/bad
+./bad.go:5:9: undefined: x
+./bad.go:9:9: cannot use "a" (untyped string constant) as int value in return statement
+	have (string)
+	want (int)
+`
+	errs := parse(output, "/go/src/example.com/bad")
+	if len(errs) != 2 {
+		t.Fatalf("want 2 errors, got %+v", errs)
+	}
+	if errs[0].File != "/go/src/example.com/bad/bad.go" || errs[0].Line != 5 || errs[0].Col != 9 {
+		t.Fatalf("want position bad.go:5:9, got %+v", errs[0])
+	}
+	if !strings.HasSuffix(errs[1].Message, "have (string)\nwant (int)") {
+		t.Fatalf("want the detail is in message, got %q", errs[1].Message)
+	}
+	if errs := parse("go: cannot find main module\n", "/a"); len(errs) != 1 || errs[0].File != "" {
+		t.Fatalf("want a error without position, got %+v", errs)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gbuild
+
+import (
+	"fmt"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+// HTitle return the title of go build html report.
+func (r *Report) HTitle() string {
+	return "go build"
+}
+
+// HSummary return the compiled package count and compile error count.
+func (r *Report) HSummary() string {
+	failed, errs := 0, 0
+	for _, p := range r.Packages {
+		if p.Failed() {
+			failed++
+			errs += len(p.Errors)
+		}
+	}
+	level := formater.HTMLLevelPass
+	if failed > 0 {
+		level = formater.HTMLLevelFail
+	}
+	s := formater.HTMLStat("packages", len(r.Packages), formater.HTMLLevelInfo) +
+		formater.HTMLStat("failed", failed, level) +
+		formater.HTMLStat("errors", errs, level) +
+		formater.HTMLStat("seconds", fmt.Sprintf("%.2f", r.Cost), formater.HTMLLevelInfo)
+	if r.SysErr != nil {
+		s += formater.HTMLStat("error", r.SysErr.Error(), formater.HTMLLevelFail)
+	}
+	return s
+}
+
+// HGroupDetail return the compile errors grouped by package.
+func (r *Report) HGroupDetail() []string {
+	var groups []string
+	for _, p := range r.Packages {
+		if !p.Failed() {
+			continue
+		}
+		rows := make([]formater.HTMLRow, 0, len(p.Errors))
+		for _, e := range p.Errors {
+			pos := ""
+			if e.File != "" {
+				pos = fmt.Sprintf("%s:%d:%d", e.File, e.Line, e.Col)
+			}
+			rows = append(rows, formater.HTMLRow{
+				Level: formater.HTMLLevelFail,
+				Cells: []interface{}{pos, e.Message},
+			})
+		}
+		title := fmt.Sprintf("%s (%d)", p.Name, len(p.Errors))
+		body := formater.HTMLTable([]string{"position", "error"}, rows)
+		groups = append(groups, formater.HTMLDetails(title, body, formater.HTMLLevelFail, false))
+	}
+	return groups
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gbuild
+
+import "time"
+
+// JSONReport the json schema of go build report, see reporter.JSONService.Decode.
+type JSONReport struct {
+	Created  time.Time      `json:"created"`
+	Cost     float32        `json:"cost"` // the seconds of go build run
+	ExecPath string         `json:"exec_path"`
+	Packages []*JSONPackage `json:"packages"`
+}
+
+// JSONPackage the json schema of a compiled package.
+type JSONPackage struct {
+	Name   string      `json:"name"` // import path
+	Dir    string      `json:"dir"`
+	Failed bool        `json:"failed"`
+	Errors []JSONError `json:"errors"`
+}
+
+// JSONError the json schema of a compile error.
+type JSONError struct {
+	File    string `json:"file,omitempty"` // empty if the error has not position
+	Line    int    `json:"line,omitempty"`
+	Col     int    `json:"col,omitempty"`
+	Message string `json:"message"`
+}
+
+// JName return the name of report in json.
+func (r *Report) JName() string {
+	return "gbuild"
+}
+
+// JReport return the report which is encoded to json, it is decoded by JSONReport.
+func (r *Report) JReport() interface{} {
+	report := &JSONReport{
+		Created:  r.Created,
+		Cost:     r.Cost,
+		ExecPath: r.ExecPath,
+		Packages: []*JSONPackage{},
+	}
+	for _, p := range r.Packages {
+		jp := &JSONPackage{Name: p.Name, Dir: p.Dir, Failed: p.Failed(), Errors: make([]JSONError, 0, len(p.Errors))}
+		for _, e := range p.Errors {
+			jp.Errors = append(jp.Errors, JSONError{File: e.File, Line: e.Line, Col: e.Col, Message: e.Message})
+		}
+		report.Packages = append(report.Packages, jp)
+	}
+	return report
+}
+
+// JError return the error of go build.
+func (r *Report) JError() error {
+	return r.SysErr
+}
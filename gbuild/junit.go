@@ -0,0 +1,58 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gbuild
+
+import "github.com/ysqi/gcodesharp/reporter/formater"
+
+// ToJunit convert Report to JUnit test suites.
+// each compiled package is a test case in Junit,
+// and the package which can not be compiled is failed with its compile errors.
+func (r *Report) ToJunit() (formater.JUnitTestSuites, error) {
+	ts := formater.JUnitTestSuite{
+		Time:      r.Cost,
+		Name:      r.ExecPath,
+		Timestamp: r.Created.UTC().Format("2006-01-02T15:04:05"), //ISO8601
+	}
+	ts.Properties = []formater.JUnitProperty{
+		{Name: "go.version", Value: r.Env.GoVersion},
+		{Name: "os", Value: r.Env.OS},
+		{Name: "arch", Value: r.Env.Arch},
+	}
+
+	if r.SysErr != nil {
+		ts.Err = r.SysErr.Error()
+	}
+	// individual test cases
+	for _, p := range r.Packages {
+		testCase := formater.JUnitTestCase{
+			Classname: "gbuild",
+			Name:      p.Name,
+		}
+		if p.Failed() {
+			testCase.Failure = &formater.JUnitFailure{
+				Message:  "build failed",
+				Type:     "ERROR",
+				Contents: p.ErrorContent(),
+			}
+			ts.Failures++
+		}
+		ts.TestCases = append(ts.TestCases, testCase)
+	}
+	ts.Tests = len(ts.TestCases)
+	return formater.JUnitTestSuites{
+		Suites: []formater.JUnitTestSuite{ts},
+	}, nil
+}
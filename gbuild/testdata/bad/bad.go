@@ -0,0 +1,6 @@
+package bad
+
+// Bad can not be compiled.
+func Bad() int {
+	return x
+}
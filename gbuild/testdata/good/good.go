@@ -0,0 +1,6 @@
+package good
+
+// Add return the sum of a and b.
+func Add(a, b int) int {
+	return a + b
+}
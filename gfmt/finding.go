@@ -0,0 +1,64 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gfmt
+
+import "github.com/ysqi/gcodesharp/reporter"
+
+// ToolName the tool name of finding.
+const ToolName = "gofmt"
+
+// Tool return the tool name of findings.
+func (r *Report) Tool() string {
+	return ToolName
+}
+
+// Findings return a finding for each file which need format, the diff is the suggested fix.
+func (r *Report) Findings() []reporter.Finding {
+	var list []reporter.Finding
+	for _, f := range r.Files {
+		if f.NeedFmt {
+			list = append(list, fileFinding(f))
+		}
+	}
+	return list
+}
+
+// Suppress mark the file which is dropped as formatted.
+func (r *Report) Suppress(drop func(reporter.Finding) bool) {
+	for _, f := range r.Files {
+		if f.NeedFmt && drop(fileFinding(f)) {
+			f.NeedFmt = false
+			f.Diff = ""
+		}
+	}
+}
+
+func fileFinding(f *File) reporter.Finding {
+	// the finding is at the first changed line, so it is same as the checkstyle error of first hunk.
+	line := 0
+	if hunks := reporter.SplitHunks(f.Diff); len(hunks) > 0 {
+		line = hunks[0].Line
+	}
+	return reporter.Finding{
+		Tool:     ToolName,
+		Rule:     "gofmt",
+		Severity: reporter.SeverityWarning,
+		File:     f.Name,
+		Line:     line,
+		Message:  "file is not gofmt-ed",
+		Fix:      f.Diff,
+	}
+}
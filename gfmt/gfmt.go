@@ -17,17 +17,18 @@ package gfmt
 
 import (
 	"bytes"
+	gocontext "context"
 	"errors"
-	"log"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/reporter"
 )
 
 type errHander func(fm string, args ...interface{})
@@ -43,7 +44,7 @@ type Report struct {
 		OS        string
 		Arch      string
 	}
-	SysErr error
+	SysErr error `json:"-"` // the error of gofmt, see JError
 }
 
 var gofmtpath string
@@ -61,122 +62,91 @@ type File struct {
 	NeedFmt bool
 }
 
+// sortFiles sort the files by name, so the report is same between runs.
+func sortFiles(files []*File) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+}
+
+// Config gofmt config, it is the "gfmt" section of config file.
+type Config struct {
+	Simplify bool `yaml:"simplify"` // simplify code, same as gofmt -s, default is true
+}
+
 type Service struct {
 	Report
+	Config Config
+	// Scheduler runs the gofmt of each package, all of them run at once if nil.
+	Scheduler *reporter.Scheduler
+	// Events streams the progress of each package, the events are dropped if nil.
+	Events *reporter.EventBus
 
-	ctx *context.Context
+	ctx  *context.Context
+	jobs *reporter.Jobs
 
-	running   bool
-	completed chan struct{}
-	errh      errHander
-	exit      chan struct{}
-	waitGroup sync.WaitGroup
-
-	sync.Mutex
+	errh errHander
 }
 
 func New(ctx *context.Context, errh errHander) (*Service, error) {
 	return &Service{
+		Config: Config{
+			Simplify: true,
+		},
 		ctx:  ctx,
 		errh: errh,
-
-		completed: make(chan struct{}, 1),
-		exit:      make(chan struct{}, 3),
 	}, nil
 }
-func (s *Service) error(msg string) {
-	s.SysErr = errors.New(msg)
-	s.errh("gfmt: %s", msg)
-	s.Stop()
-}
-
-// Run go fmt
-func (s *Service) Run() error {
-	if s.running {
-		return errors.New("gfmt is running")
-	}
-	s.Lock()
-	defer s.Unlock()
 
+// Run go fmt and block until done, the gofmt processes are killed if ctx is done.
+func (s *Service) Run(ctx gocontext.Context) error {
 	s.Created = time.Now()
 	s.Env.GoVersion = runtime.Version()
 	s.Env.OS = runtime.GOOS
 	s.Env.Arch = runtime.GOARCH
 	s.GoFmt = gofmtpath
 
-	go func() {
-		wg := sync.WaitGroup{}
-		wg.Add(len(s.ctx.Packages))
-		for _, p := range s.ctx.Packages {
-			files := p.GoFiles
-			// absolute path.
-			for i := 0; i < len(files); i++ {
-				if !filepath.IsAbs(files[i]) {
-					files[i] = filepath.Join(p.Dir, files[i])
-				}
-			}
-			// batch gofmt
-			go func(files []string) {
-				defer wg.Done()
-				select {
-				default:
-				case <-s.exit:
-					return
-				}
-				result := s.gofmt(files)
-				s.Report.Files = append(s.Report.Files, result...)
-
-			}(files)
-
-			//abort the foreach if exit
-			select {
-			case <-s.exit:
-				break
-			default:
+	s.jobs = reporter.NewJobs(ctx, "gfmt", s.Scheduler, s.Events, s.errh)
+	ctx = s.jobs.Context()
+	results := make([][]*File, len(s.ctx.Packages))
+	for i, p := range s.ctx.Packages {
+		// absolute path, the package is shared by services so its files are not changed.
+		files := make([]string, 0, len(p.GoFiles))
+		for _, f := range p.GoFiles {
+			if !filepath.IsAbs(f) {
+				f = filepath.Join(p.Dir, f)
 			}
+			files = append(files, f)
 		}
-		go func() {
-			// wait for all go fmt done
-			wg.Wait()
-			s.Cost = float32(time.Since(s.Created).Seconds())
-			close(s.completed)
-		}()
-	}()
-	s.running = true
-	return nil
-}
-
-func (s *Service) Stop() error {
-	if !s.running {
-		return nil
-	}
-	s.Lock()
-	defer s.Unlock()
-	close(s.exit)
-	s.running = false
-	return nil
-}
-
-func (s *Service) Wait() error {
-	if !s.running {
-		return nil
-	}
-	for {
-		select {
-		case <-s.exit:
-			return nil
-		case <-s.completed:
-			return nil
-		case <-time.After(1 * time.Second):
+		// only the changed files are checked in diff-aware mode.
+		files = s.ctx.ChangedFiles(files)
+		if len(files) == 0 {
+			// e.g: the package can not be loaded.
+			continue
 		}
+		// batch gofmt
+		i := i
+		s.jobs.Go(p.ImportPath, func() (bool, []reporter.Finding) {
+			results[i] = s.gofmt(ctx, files)
+			findings := (&Report{Files: results[i]}).Findings()
+			return len(findings) > 0, findings
+		})
+	}
+	// wait for all go fmt done
+	s.SysErr = s.jobs.Wait()
+	for _, result := range results {
+		s.Report.Files = append(s.Report.Files, result...)
 	}
-	s.running = false
-	return nil
+	sortFiles(s.Report.Files)
+	s.Cost = float32(time.Since(s.Created).Seconds())
+	return s.SysErr
 }
-func (s *Service) gofmt(files []string) []*File {
-	result, err := runGoFmt(files...)
-	if err != nil {
-		s.error(err.Error())
+
+func (s *Service) gofmt(ctx gocontext.Context, files []string) []*File {
+	result, err := runGoFmt(ctx, s.Config.Simplify, files...)
+	// the error of killed gofmt is not a failure.
+	if err != nil && ctx.Err() == nil {
+		s.jobs.Error(err.Error())
 	}
 	return result
 }
@@ -190,25 +160,28 @@ var (
 	regDiffHead = regexp.MustCompile(`^diff(?: -u){0,1} \S+\s(?:gofmt\/){0,1}(\S+)$`)
 )
 
-func runGoFmt(files ...string) ([]*File, error) {
+func runGoFmt(ctx gocontext.Context, simplify bool, files ...string) ([]*File, error) {
 
 	var (
 		stderr bytes.Buffer
 		stdout bytes.Buffer
 	)
-	cmd := exec.Command(gofmtpath, "-d", "-e", "-s")
+	cmd := exec.Command(gofmtpath, "-d", "-e")
+	if simplify {
+		cmd.Args = append(cmd.Args, "-s")
+	}
 	cmd.Args = append(cmd.Args, files...)
 	cmd.Stderr = &stderr
 	cmd.Stdout = &stdout
-	if err := cmd.Start(); err != nil {
-		return nil, err
-	}
-	if err := cmd.Wait(); err != nil {
+	if err := context.RunCommand(ctx, cmd); err != nil {
 		s := stderr.String()
 		if s != "" {
 			return nil, errors.New(stderr.String() + "\n" + err.Error())
 		}
-		return nil, err
+		// gofmt -d exit with status 1 if has diff in the newer go version
+		if _, ok := err.(*exec.ExitError); !ok || stdout.Len() == 0 {
+			return nil, err
+		}
 	}
 	var result []*File
 	for _, f := range files {
@@ -219,9 +192,6 @@ func runGoFmt(files ...string) ([]*File, error) {
 	// read output add add to diffrent file
 	var file *File
 	for _, line := range strings.Split(stdout.String(), "\n") {
-		if line != "" {
-			log.Println(line)
-		}
 		if matches := regDiffHead.FindSubmatch([]byte(line)); len(matches) == 2 {
 			// find file
 			name := string(matches[1])
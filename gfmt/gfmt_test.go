@@ -16,11 +16,14 @@
 package gfmt
 
 import (
-	"go/build"
+	gocontext "context"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/reporter"
 )
 
 func TestRun(t *testing.T) {
@@ -29,19 +32,20 @@ func TestRun(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	p, err := build.Import("github.com/ysqi/gcodesharp/gfmt/testdata", "", build.IgnoreVendor)
+	pkgs, err := context.ListPackages("", "./testdata")
 	if err != nil {
 		t.Fatal(err)
 	}
-	ctx.Packages = append(ctx.Packages, p)
+	ctx.Packages = append(ctx.Packages, pkgs...)
 	s, err := New(ctx, func(fmt_ string, args ...interface{}) {
 		t.Fatalf(fmt_, args...)
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
-	s.Run()
-	s.Wait()
+	if err := s.Run(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
 	if s.Report.GoFmt != gofmtpath {
 		t.Fatal("need gofmt path value")
 	}
@@ -50,8 +54,45 @@ func TestRun(t *testing.T) {
 	}
 }
 
+// the files are merged in name order whatever the package order and the finished order of jobs.
+func TestRunSorted(t *testing.T) {
+	pkgs, err := context.ListPackages("", "./testdata", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][]string
+	for _, order := range [][]*context.Package{pkgs, {pkgs[1], pkgs[0]}} {
+		ctx, err := context.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx.Packages = order
+		s, err := New(ctx, func(fmt_ string, args ...interface{}) {
+			t.Fatalf(fmt_, args...)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Scheduler = reporter.NewScheduler(2, nil)
+		if err := s.Run(gocontext.Background()); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, f := range s.Report.Files {
+			names = append(names, f.Name)
+		}
+		if !sort.StringsAreSorted(names) {
+			t.Fatalf("want files sorted by name, got %v", names)
+		}
+		got = append(got, names)
+	}
+	if !reflect.DeepEqual(got[0], got[1]) {
+		t.Fatalf("want same report of runs, got %v and %v", got[0], got[1])
+	}
+}
+
 func TestGoFmt(t *testing.T) {
-	files, err := runGoFmt("gfmt_test.go")
+	files, err := runGoFmt(gocontext.Background(), true, "gfmt_test.go")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -60,7 +101,7 @@ func TestGoFmt(t *testing.T) {
 		t.Fatal("want need format but got no need")
 	}
 
-	files, err = runGoFmt("gfmt.go", "gfmt_test2.go")
+	files, err = runGoFmt(gocontext.Background(), true, "gfmt.go", "gfmt_test2.go")
 	if err == nil {
 		t.Fatal("need error,but got nil")
 	}
@@ -70,7 +111,7 @@ func TestGoFmt(t *testing.T) {
 		}
 	}
 
-	files, err = runGoFmt("./testdata/needFmt.go", "./testdata/needFmt2.go")
+	files, err = runGoFmt(gocontext.Background(), true, "./testdata/needFmt.go", "./testdata/needFmt2.go")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -84,4 +125,12 @@ func TestGoFmt(t *testing.T) {
 
 	}
 
+	findings := (&Report{Files: files}).Findings()
+	if len(findings) != 2 {
+		t.Fatalf("want 2 findings, got %d", len(findings))
+	}
+	// the hunk starts at line 18, the first changed line is 21.
+	if f := findings[0]; f.Line != 21 || f.Fix != files[0].Diff {
+		t.Fatalf("want finding at line 21 with diff fix, got line %d", f.Line)
+	}
 }
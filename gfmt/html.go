@@ -0,0 +1,66 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gfmt
+
+import (
+	"fmt"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+// HTitle return the title of gofmt html report.
+func (r *Report) HTitle() string {
+	return "gofmt"
+}
+
+// HSummary return the checked and need format file count.
+func (r *Report) HSummary() string {
+	need := r.NeedFmtCount()
+	level := formater.HTMLLevelPass
+	if need > 0 {
+		level = formater.HTMLLevelWarn
+	}
+	s := formater.HTMLStat("files checked", len(r.Files), formater.HTMLLevelInfo) +
+		formater.HTMLStat("need format", need, level) +
+		formater.HTMLStat("seconds", fmt.Sprintf("%.2f", r.Cost), formater.HTMLLevelInfo)
+	if r.SysErr != nil {
+		s += formater.HTMLStat("error", r.SysErr.Error(), formater.HTMLLevelFail)
+	}
+	return s
+}
+
+// HGroupDetail return a collapsible diff for each need format go file.
+func (r *Report) HGroupDetail() []string {
+	var groups []string
+	for _, f := range r.Files {
+		if !f.NeedFmt {
+			continue
+		}
+		groups = append(groups, formater.HTMLDiff(f.Name, f.Diff, formater.HTMLLevelWarn, false))
+	}
+	return groups
+}
+
+// NeedFmtCount counts the number of need format go files.
+func (r *Report) NeedFmtCount() int {
+	count := 0
+	for _, f := range r.Files {
+		if f.NeedFmt {
+			count++
+		}
+	}
+	return count
+}
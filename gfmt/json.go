@@ -0,0 +1,57 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gfmt
+
+import "time"
+
+// JSONReport the json schema of gofmt report, see reporter.JSONService.Decode.
+type JSONReport struct {
+	Created  time.Time   `json:"created"`
+	Cost     float32     `json:"cost"` // the seconds of gofmt run
+	ExecPath string      `json:"exec_path"`
+	Files    []*JSONFile `json:"files"`
+}
+
+// JSONFile the json schema of a checked go file.
+type JSONFile struct {
+	Name    string `json:"name"`
+	NeedFmt bool   `json:"need_fmt"`
+	Diff    string `json:"diff,omitempty"` // the diff of gofmt if it needs format
+}
+
+// JName return the name of report in json.
+func (r *Report) JName() string {
+	return "gfmt"
+}
+
+// JReport return the report which is encoded to json, it is decoded by JSONReport.
+func (r *Report) JReport() interface{} {
+	report := &JSONReport{
+		Created:  r.Created,
+		Cost:     r.Cost,
+		ExecPath: r.GoFmt,
+		Files:    []*JSONFile{},
+	}
+	for _, f := range r.Files {
+		report.Files = append(report.Files, &JSONFile{Name: f.Name, NeedFmt: f.NeedFmt, Diff: f.Diff})
+	}
+	return report
+}
+
+// JError return the error of gofmt.
+func (r *Report) JError() error {
+	return r.SysErr
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package glint
+
+import (
+	"strings"
+
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// ToolName the tool name of finding.
+const ToolName = "golint"
+
+// Tool return the tool name of findings.
+func (r *Report) Tool() string {
+	return ToolName
+}
+
+// Findings return a finding for each golint problem.
+func (r *Report) Findings() []reporter.Finding {
+	var list []reporter.Finding
+	for _, f := range r.Files {
+		for _, p := range f.Problem {
+			list = append(list, problemFinding(f, p))
+		}
+	}
+	return list
+}
+
+// Suppress remove the problem which is dropped.
+func (r *Report) Suppress(drop func(reporter.Finding) bool) {
+	for _, f := range r.Files {
+		var kept []Problem
+		for _, p := range f.Problem {
+			if !drop(problemFinding(f, p)) {
+				kept = append(kept, p)
+			}
+		}
+		f.Problem = kept
+	}
+}
+
+func problemFinding(f *File, p Problem) reporter.Finding {
+	return reporter.Finding{
+		Tool:     ToolName,
+		Rule:     "golint",
+		Severity: reporter.SeverityWarning,
+		File:     f.Name,
+		Line:     p.Line,
+		Col:      p.Cell,
+		Message:  strings.TrimSpace(p.Info),
+	}
+}
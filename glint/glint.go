@@ -17,19 +17,20 @@ package glint
 
 import (
 	"bytes"
+	gocontext "context"
 	"errors"
 	"fmt"
-	"log"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/reporter"
 )
 
 type errHander func(fm string, args ...interface{})
@@ -45,7 +46,7 @@ type Report struct {
 		OS        string
 		Arch      string
 	}
-	SysErr error
+	SysErr error `json:"-"` // the error of golint, see JError
 }
 
 type Problem struct {
@@ -62,6 +63,13 @@ type File struct {
 	Problem []Problem
 }
 
+// sortFiles sort the files by name, so the report is same between runs.
+func sortFiles(files []*File) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+}
+
 func (f *File) HasProblem() bool {
 	return len(f.Problem) > 0
 }
@@ -79,121 +87,94 @@ func (f *File) ProblemContent() string {
 	return str.String()
 }
 
+// Config golint config, it is the "glint" section of config file.
+type Config struct {
+	MinConfidence float64 `yaml:"min_confidence"` // minimum confidence of a problem to print it, default is 0.8
+}
+
 type Service struct {
 	Report
+	Config Config
+	// Scheduler runs the golint of each package, all of them run at once if nil.
+	Scheduler *reporter.Scheduler
+	// Events streams the progress of each package, the events are dropped if nil.
+	Events *reporter.EventBus
 
-	ctx *context.Context
+	ctx  *context.Context
+	jobs *reporter.Jobs
 
-	running   bool
-	completed chan struct{}
-	errh      errHander
-	exit      chan struct{}
-	waitGroup sync.WaitGroup
-
-	sync.Mutex
+	errh errHander
 }
 
 func New(ctx *context.Context, errh errHander) (*Service, error) {
 	return &Service{
+		Config: Config{
+			MinConfidence: 0.8,
+		},
 		ctx:  ctx,
 		errh: errh,
-
-		completed: make(chan struct{}, 1),
-		exit:      make(chan struct{}, 3),
 	}, nil
 }
-func (s *Service) error(msg string) {
-	s.SysErr = errors.New(msg)
-	s.errh("glint: %s", msg)
-	s.Stop()
-}
-
-// Run go lint
-func (s *Service) Run() error {
-	if s.running {
-		return errors.New("glint is running")
-	}
-	s.Lock()
-	defer s.Unlock()
 
+// Run go lint and block until done, the golint processes are killed if ctx is done.
+func (s *Service) Run(ctx gocontext.Context) error {
 	s.Created = time.Now()
 	s.Env.GoVersion = runtime.Version()
 	s.Env.OS = runtime.GOOS
 	s.Env.Arch = runtime.GOARCH
 	s.ExecPath = "golint"
-	go func() {
-		wg := sync.WaitGroup{}
-		wg.Add(len(s.ctx.Packages))
-		for _, p := range s.ctx.Packages {
-			files := p.GoFiles
-			// absolute path.
-			for i := 0; i < len(files); i++ {
-				if !filepath.IsAbs(files[i]) {
-					files[i] = filepath.Join(p.Dir, files[i])
-				}
-			}
-			// batch golint
-			go func(files []string) {
-				defer wg.Done()
-				select {
-				default:
-				case <-s.exit:
-					return
-				}
-				result := s.golint(files)
-				s.Report.Files = append(s.Report.Files, result...)
-
-			}(files)
 
-			//abort the foreach if exit
-			select {
-			case <-s.exit:
-				break
-			default:
+	s.jobs = reporter.NewJobs(ctx, "glint", s.Scheduler, s.Events, s.errh)
+	ctx = s.jobs.Context()
+	results := make([][]*File, len(s.ctx.Packages))
+	for i, p := range s.ctx.Packages {
+		// absolute path, the package is shared by services so its files are not changed.
+		files := make([]string, 0, len(p.GoFiles))
+		for _, f := range p.GoFiles {
+			if !filepath.IsAbs(f) {
+				f = filepath.Join(p.Dir, f)
 			}
+			files = append(files, f)
 		}
-		go func() {
-			// wait for all go lint done
-			wg.Wait()
-			s.Cost = float32(time.Since(s.Created).Seconds())
-			close(s.completed)
-		}()
-	}()
-	s.running = true
-	return nil
-}
-
-func (s *Service) Stop() error {
-	if !s.running {
-		return nil
+		// only the changed files are checked in diff-aware mode.
+		files = s.ctx.ChangedFiles(files)
+		if len(files) == 0 {
+			// e.g: the package can not be loaded.
+			continue
+		}
+		// batch golint
+		i := i
+		s.jobs.Go(p.ImportPath, func() (bool, []reporter.Finding) {
+			results[i] = s.golint(ctx, files)
+			findings := (&Report{Files: results[i]}).Findings()
+			return len(findings) > 0, findings
+		})
+	}
+	// wait for all go lint done
+	s.SysErr = s.jobs.Wait()
+	for _, result := range results {
+		s.Report.Files = append(s.Report.Files, result...)
 	}
-	s.Lock()
-	defer s.Unlock()
-	close(s.exit)
-	s.running = false
-	return nil
+	sortFiles(s.Report.Files)
+	s.Cost = float32(time.Since(s.Created).Seconds())
+	return s.SysErr
 }
 
-func (s *Service) Wait() error {
-	if !s.running {
-		return nil
+func (s *Service) golint(ctx gocontext.Context, files []string) []*File {
+	result, err := runGolint(ctx, s.Config.MinConfidence, files...)
+	// the error of killed golint is not a failure.
+	if err != nil && ctx.Err() == nil {
+		s.jobs.Error(err.Error())
 	}
-	for {
-		select {
-		case <-s.exit:
-			return nil
-		case <-s.completed:
-			return nil
-		case <-time.After(1 * time.Second):
+	// only the problems on changed lines are reported in diff-aware mode.
+	for _, f := range result {
+		var kept []Problem
+		for _, p := range f.Problem {
+			if s.ctx.ChangedLine(f.Name, p.Line) {
+				kept = append(kept, p)
+			}
 		}
-	}
-	s.running = false
-	return nil
-}
-func (s *Service) golint(files []string) []*File {
-	result, err := runGolint(files...)
-	if err != nil {
-		s.error(err.Error())
+		f.Problem = kept
 	}
 	return result
 }
@@ -203,20 +184,17 @@ var (
 	regLine = regexp.MustCompile(`^(.+\.go):(\d+):(\d+):(.*)$`)
 )
 
-func runGolint(files ...string) ([]*File, error) {
+func runGolint(ctx gocontext.Context, minConfidence float64, files ...string) ([]*File, error) {
 
 	var (
 		stderr bytes.Buffer
 		stdout bytes.Buffer
 	)
-	cmd := exec.Command("golint")
+	cmd := exec.Command("golint", "-min_confidence", strconv.FormatFloat(minConfidence, 'f', -1, 64))
 	cmd.Args = append(cmd.Args, files...)
 	cmd.Stderr = &stderr
 	cmd.Stdout = &stdout
-	if err := cmd.Start(); err != nil {
-		return nil, err
-	}
-	if err := cmd.Wait(); err != nil {
+	if err := context.RunCommand(ctx, cmd); err != nil {
 		s := stderr.String()
 		if s != "" {
 			return nil, errors.New(stderr.String() + "\n" + err.Error())
@@ -232,9 +210,6 @@ func runGolint(files ...string) ([]*File, error) {
 	// read output add add to file
 	var file *File
 	for _, line := range strings.Split(stdout.String(), "\n") {
-		if line != "" {
-			log.Println(line)
-		}
 		if matches := regLine.FindSubmatch([]byte(line)); len(matches) == 5 {
 			// find file
 			name := string(matches[1])
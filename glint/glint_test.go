@@ -16,7 +16,7 @@
 package glint
 
 import (
-	"go/build"
+	gocontext "context"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -30,19 +30,20 @@ func TestRun(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	p, err := build.Import("github.com/ysqi/gcodesharp/glint/testdata", "", build.IgnoreVendor)
+	pkgs, err := context.ListPackages("", "./testdata")
 	if err != nil {
 		t.Fatal(err)
 	}
-	ctx.Packages = append(ctx.Packages, p)
+	ctx.Packages = append(ctx.Packages, pkgs...)
 	s, err := New(ctx, func(fmt_ string, args ...interface{}) {
 		t.Fatalf(fmt_, args...)
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
-	s.Run()
-	s.Wait()
+	if err := s.Run(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
 
 	if f := find(t, s.Report.Files, "bad1.go"); f == nil {
 		t.Fatal("want report ba1.go problem")
@@ -0,0 +1,71 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package glint
+
+import (
+	"fmt"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+// HTitle return the title of golint html report.
+func (r *Report) HTitle() string {
+	return "golint"
+}
+
+// HSummary return the checked file count and problem count.
+func (r *Report) HSummary() string {
+	files, problems := 0, 0
+	for _, f := range r.Files {
+		if f.HasProblem() {
+			files++
+			problems += len(f.Problem)
+		}
+	}
+	level := formater.HTMLLevelPass
+	if problems > 0 {
+		level = formater.HTMLLevelWarn
+	}
+	s := formater.HTMLStat("files checked", len(r.Files), formater.HTMLLevelInfo) +
+		formater.HTMLStat("files with problem", files, level) +
+		formater.HTMLStat("problems", problems, level) +
+		formater.HTMLStat("seconds", fmt.Sprintf("%.2f", r.Cost), formater.HTMLLevelInfo)
+	if r.SysErr != nil {
+		s += formater.HTMLStat("error", r.SysErr.Error(), formater.HTMLLevelFail)
+	}
+	return s
+}
+
+// HGroupDetail return the problems grouped by go file.
+func (r *Report) HGroupDetail() []string {
+	var groups []string
+	for _, f := range r.Files {
+		if !f.HasProblem() {
+			continue
+		}
+		rows := make([]formater.HTMLRow, 0, len(f.Problem))
+		for _, p := range f.Problem {
+			rows = append(rows, formater.HTMLRow{
+				Level: formater.HTMLLevelWarn,
+				Cells: []interface{}{fmt.Sprintf("%d:%d", p.Line, p.Cell), p.Info},
+			})
+		}
+		title := fmt.Sprintf("%s (%d)", f.Name, len(f.Problem))
+		body := formater.HTMLTable([]string{"line", "problem"}, rows)
+		groups = append(groups, formater.HTMLDetails(title, body, formater.HTMLLevelWarn, false))
+	}
+	return groups
+}
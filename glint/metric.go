@@ -0,0 +1,31 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package glint
+
+import (
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// Metrics return the count of lint problems for quality gate.
+func (r *Report) Metrics() []reporter.Metric {
+	problems := 0
+	for _, f := range r.Files {
+		problems += len(f.Problem)
+	}
+	return []reporter.Metric{
+		{Name: reporter.MetricLintProblems, Value: float64(problems)},
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// Name return the service name which other services depend on.
+func (s *Service) Name() string {
+	return "gtest"
+}
+
+// Depends return gbuild, so the package which can not be compiled is not tested.
+func (s *Service) Depends() []string {
+	return []string{"gbuild"}
+}
+
+// Consume read the build errors of gbuild, it is ignored if gbuild is not run.
+func (s *Service) Consume(a *reporter.Artifacts) error {
+	err := a.Get(reporter.ArtifactBuildErrors, &s.buildErrors)
+	if err == reporter.ErrArtifactNotFound {
+		return nil
+	}
+	return err
+}
+
+// Produce put the merged cover profiles of all tested packages, e.g: for coverage annotation.
+func (s *Service) Produce(a *reporter.Artifacts) {
+	a.Put(reporter.ArtifactCoverProfiles, s.Report.CoverProfiles())
+}
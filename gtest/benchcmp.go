@@ -0,0 +1,229 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// The units of benchmark which are compared, the larger value is worse.
+var compareUnits = []string{"ns/op", "B/op", "allocs/op"}
+
+// BenchStat the statistic of the samples of a benchmark metric.
+type BenchStat struct {
+	N      int // the number of samples
+	Mean   float64
+	StdDev float64 // the sample standard deviation, zero if less than two samples
+}
+
+// CV return the coefficient of variation in percent, it is zero if the mean is zero.
+func (s BenchStat) CV() float64 {
+	if s.Mean == 0 {
+		return 0
+	}
+	return s.StdDev / s.Mean * 100
+}
+
+// BenchDelta the change of a benchmark metric between two runs.
+type BenchDelta struct {
+	Package string
+	Name    string // the benchmark name with GOMAXPROCS suffix, e.g: BenchmarkFoo-8
+	Unit    string // e.g: ns/op
+	Old     BenchStat
+	New     BenchStat
+	// Delta the change of the mean in percent, it is +Inf if the old mean is zero and the new is not.
+	Delta float64
+	// Low and High the 95% confidence interval of Delta in percent.
+	Low, High float64
+	// Significant is true if the confidence interval does not contain zero,
+	// it is always false if any side has less than two samples.
+	Significant bool
+}
+
+// Regressed check the metric is significantly larger than the threshold percent.
+func (d BenchDelta) Regressed(threshold float64) bool {
+	return d.Significant && d.Delta > threshold
+}
+
+// Improved check the metric is significantly smaller.
+func (d BenchDelta) Improved() bool {
+	return d.Significant && d.Delta < 0
+}
+
+// ParseBenchmarks parse the benchmark results of go test output, the output can be the text or json.
+// return the benchmark results of each package.
+func ParseBenchmarks(r io.Reader) (map[string][]*Benchmark, error) {
+	pkgs, err := parseOutput(r, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string][]*Benchmark{}
+	for _, p := range pkgs {
+		if len(p.Benchmarks) > 0 {
+			result[p.Name] = append(result[p.Name], p.Benchmarks...)
+		}
+	}
+	return result, nil
+}
+
+// CompareBenchmarks compare the benchmarks which are in both before and after results,
+// the repeated results of same benchmark, e.g: run with '-count N', are the samples.
+// the ns/op, B/op and allocs/op are compared, the B/op and allocs/op are skipped if not reported.
+// the result is sorted by package, benchmark name and unit.
+func CompareBenchmarks(before, after map[string][]*Benchmark) []BenchDelta {
+	var list []BenchDelta
+	for pkg, afterList := range after {
+		beforeSamples := benchSamples(before[pkg])
+		for name, as := range benchSamples(afterList) {
+			bs, ok := beforeSamples[name]
+			if !ok {
+				continue
+			}
+			for i, unit := range compareUnits {
+				if len(bs[i]) == 0 || len(as[i]) == 0 {
+					continue
+				}
+				d := compareSamples(bs[i], as[i])
+				d.Package, d.Name, d.Unit = pkg, name, unit
+				list = append(list, d)
+			}
+		}
+	}
+	unitIndex := func(unit string) int {
+		for i, u := range compareUnits {
+			if u == unit {
+				return i
+			}
+		}
+		return len(compareUnits)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return unitIndex(a.Unit) < unitIndex(b.Unit)
+	})
+	return list
+}
+
+// benchSamples group the values of compared units by the benchmark name with GOMAXPROCS suffix.
+func benchSamples(list []*Benchmark) map[string][][]float64 {
+	samples := map[string][][]float64{}
+	for _, b := range list {
+		name := b.Name
+		if b.Procs > 1 {
+			name += "-" + strconv.Itoa(b.Procs)
+		}
+		s, ok := samples[name]
+		if !ok {
+			s = make([][]float64, len(compareUnits))
+			samples[name] = s
+		}
+		for i, unit := range compareUnits {
+			if v, ok := b.Metric(unit); ok {
+				s[i] = append(s[i], v)
+			}
+		}
+	}
+	return samples
+}
+
+// compareSamples compare the mean of samples by the Welch's t-test,
+// the confidence interval is the 95% interval of the difference of means.
+func compareSamples(before, after []float64) BenchDelta {
+	d := BenchDelta{Old: stat(before), New: stat(after)}
+	diff := d.New.Mean - d.Old.Mean
+	// can not estimate the variance if less than two samples
+	margin := math.Inf(1)
+	if d.Old.N >= 2 && d.New.N >= 2 {
+		margin = 0
+		v1 := d.Old.StdDev * d.Old.StdDev / float64(d.Old.N)
+		v2 := d.New.StdDev * d.New.StdDev / float64(d.New.N)
+		if se := math.Sqrt(v1 + v2); se > 0 {
+			// Welch–Satterthwaite degrees of freedom
+			df := (v1 + v2) * (v1 + v2) / (v1*v1/float64(d.Old.N-1) + v2*v2/float64(d.New.N-1))
+			margin = tCritical(df) * se
+		}
+	}
+	d.Significant = diff != 0 && math.Abs(diff) > margin
+	switch {
+	case diff == 0:
+		// no change, e.g: the allocs/op is always zero
+	case d.Old.Mean == 0:
+		d.Delta = math.Inf(1)
+		d.Low, d.High = d.Delta, d.Delta
+	default:
+		d.Delta = diff / d.Old.Mean * 100
+		d.Low = (diff - margin) / d.Old.Mean * 100
+		d.High = (diff + margin) / d.Old.Mean * 100
+	}
+	return d
+}
+
+func stat(samples []float64) BenchStat {
+	s := BenchStat{N: len(samples)}
+	if s.N == 0 {
+		return s
+	}
+	for _, v := range samples {
+		s.Mean += v
+	}
+	s.Mean /= float64(s.N)
+	if s.N < 2 {
+		return s
+	}
+	var sum float64
+	for _, v := range samples {
+		sum += (v - s.Mean) * (v - s.Mean)
+	}
+	s.StdDev = math.Sqrt(sum / float64(s.N-1))
+	return s
+}
+
+// the two-sided 95% critical values of Student's t-distribution by degrees of freedom.
+var tTable = []struct {
+	df float64
+	t  float64
+}{
+	{1, 12.706}, {2, 4.303}, {3, 3.182}, {4, 2.776}, {5, 2.571},
+	{6, 2.447}, {7, 2.365}, {8, 2.306}, {9, 2.262}, {10, 2.228},
+	{11, 2.201}, {12, 2.179}, {13, 2.160}, {14, 2.145}, {15, 2.131},
+	{16, 2.120}, {17, 2.110}, {18, 2.101}, {19, 2.093}, {20, 2.086},
+	{21, 2.080}, {22, 2.074}, {23, 2.069}, {24, 2.064}, {25, 2.060},
+	{26, 2.056}, {27, 2.052}, {28, 2.048}, {29, 2.045}, {30, 2.042},
+	{40, 2.021}, {60, 2.000}, {120, 1.980},
+}
+
+// tCritical return the two-sided 95% critical value of t-distribution,
+// the fractional degrees of freedom is rounded down, so the interval is not narrower.
+func tCritical(df float64) float64 {
+	if df > tTable[len(tTable)-1].df {
+		return 1.960
+	}
+	for i := len(tTable) - 1; i > 0; i-- {
+		if tTable[i].df <= df {
+			return tTable[i].t
+		}
+	}
+	return tTable[0].t
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func loadBenchmarks(t *testing.T, file string) map[string][]*Benchmark {
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	list, err := ParseBenchmarks(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestCompareBenchmarks(t *testing.T) {
+	before := loadBenchmarks(t, "./testdata/bench_old.txt")
+	after := loadBenchmarks(t, "./testdata/bench_new.txt")
+	if len(before["package/bench"]) != 8 {
+		t.Fatalf("want 8 benchmark results, got %d", len(before["package/bench"]))
+	}
+	deltas := CompareBenchmarks(before, after)
+	if len(deltas) != 6 {
+		t.Fatalf("want 6 deltas of 2 benchmarks, got %d", len(deltas))
+	}
+	bar, foo := deltas[0], deltas[3]
+	if bar.Name != "BenchmarkBar-8" || bar.Unit != "ns/op" || foo.Name != "BenchmarkFoo-8" || foo.Unit != "ns/op" {
+		t.Fatalf("want sorted by name and unit, got %s %s and %s %s", bar.Name, bar.Unit, foo.Name, foo.Unit)
+	}
+	// the noisy change is not significant
+	if bar.Significant || bar.Low > 0 || bar.High < 0 {
+		t.Fatalf("want BenchmarkBar is not changed, got %+v", bar)
+	}
+	if !foo.Regressed(5) || foo.Regressed(20) || math.Abs(foo.Delta-19.2) > 0.01 {
+		t.Fatalf("want BenchmarkFoo regress 19.2%%, got %+v", foo)
+	}
+	if foo.Low < 5 || foo.High > 25 {
+		t.Fatalf("want the confidence interval of BenchmarkFoo in [5%%,25%%], got [%.2f%%,%.2f%%]", foo.Low, foo.High)
+	}
+	// the constant allocs/op change is significant
+	if allocs := deltas[5]; allocs.Unit != "allocs/op" || !allocs.Significant || allocs.Delta != 100 {
+		t.Fatalf("want allocs/op of BenchmarkFoo increase 100%%, got %+v", allocs)
+	}
+}
+
+func TestCompareSamples(t *testing.T) {
+	// can not know the variance of single sample
+	if d := compareSamples([]float64{1}, []float64{2}); d.Significant || d.Delta != 100 {
+		t.Fatalf("want not significant change of single sample, got %+v", d)
+	}
+	if d := compareSamples([]float64{0, 0}, []float64{1, 1}); !d.Significant || !math.IsInf(d.Delta, 1) {
+		t.Fatalf("want +Inf change from zero, got %+v", d)
+	}
+	if tCritical(2.7) != 4.303 || tCritical(1000) != 1.960 || tCritical(0.5) != 12.706 {
+		t.Fatal("want the critical value of t-distribution from table")
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+// Benchmark the result of a benchmark run, the benchmark run with '-count N' has N results.
+type Benchmark struct {
+	// Name the full name of benchmark without the GOMAXPROCS suffix, e.g: BenchmarkFoo/small
+	Name string
+	// Procs the GOMAXPROCS of benchmark run, e.g: 8 of BenchmarkFoo-8, it is 1 if no suffix.
+	Procs int
+	// N the iterations of benchmark.
+	N           int
+	NsPerOp     float64
+	BytesPerOp  float64 // it is set if run with -benchmem or b.ReportAllocs
+	AllocsPerOp float64 // it is set if run with -benchmem or b.ReportAllocs
+	MBPerSec    float64 // it is set if b.SetBytes is called
+	// Mem is true if the B/op and allocs/op are reported.
+	Mem bool
+	// Metrics the custom metrics reported by b.ReportMetric, e.g: 3.5 widgets/op
+	Metrics []BenchMetric
+}
+
+// BenchMetric a custom metric of benchmark.
+type BenchMetric struct {
+	Unit  string
+	Value float64
+}
+
+// Metric return the value of metric by unit, both the standard metrics and the custom metrics,
+// e.g: ns/op, B/op, allocs/op, MB/s and widgets/op. ok is false if not reported.
+func (b *Benchmark) Metric(unit string) (value float64, ok bool) {
+	switch unit {
+	case "ns/op":
+		return b.NsPerOp, true
+	case "B/op":
+		return b.BytesPerOp, b.Mem
+	case "allocs/op":
+		return b.AllocsPerOp, b.Mem
+	case "MB/s":
+		return b.MBPerSec, b.MBPerSec > 0
+	}
+	for _, m := range b.Metrics {
+		if m.Unit == unit {
+			return m.Value, true
+		}
+	}
+	return 0, false
+}
+
+// String return the benchmark result line like go test print.
+func (b *Benchmark) String() string {
+	var s strings.Builder
+	s.WriteString(b.Name)
+	if b.Procs > 1 {
+		fmt.Fprintf(&s, "-%d", b.Procs)
+	}
+	fmt.Fprintf(&s, "\t%d\t%s ns/op", b.N, formatFloat(b.NsPerOp))
+	if b.MBPerSec > 0 {
+		fmt.Fprintf(&s, "\t%s MB/s", formatFloat(b.MBPerSec))
+	}
+	for _, m := range b.Metrics {
+		fmt.Fprintf(&s, "\t%s %s", formatFloat(m.Value), m.Unit)
+	}
+	if b.Mem {
+		fmt.Fprintf(&s, "\t%s B/op\t%s allocs/op", formatFloat(b.BytesPerOp), formatFloat(b.AllocsPerOp))
+	}
+	return s.String()
+}
+
+var (
+	// the result line of benchmark, like:
+	//	BenchmarkFoo-8   	 1000000	      1234 ns/op	      16 B/op	       1 allocs/op
+	//	BenchmarkBar/small         	     100	         3.730 ns/op	         3.500 widgets/op
+	regBenchResult = regexp.MustCompile(`^(Benchmark\S*?)(?:-(\d+))?\s+(\d+)\s+(\S+ ns/op(?:\s+\S+ \S+)*)\s*$`)
+	// the line before the result of benchmark, the benchmark name and the environment, like:
+	//	BenchmarkFoo
+	//	goos: linux
+	//	goarch: amd64
+	//	pkg: github.com/ysqi/com
+	//	cpu: Intel(R) Xeon(R) Processor @ 2.10GHz
+	regBenchInfo = regexp.MustCompile(`^(?:Benchmark\S*|(?:goos|goarch|pkg|cpu): .+)$`)
+)
+
+// parseBenchmark parse the result line of benchmark, return nil if it is not a result line.
+func parseBenchmark(line string) *Benchmark {
+	matches := regBenchResult.FindStringSubmatch(strings.TrimSpace(line))
+	if len(matches) != 5 {
+		return nil
+	}
+	b := &Benchmark{
+		Name:  matches[1],
+		Procs: 1,
+	}
+	if matches[2] != "" {
+		b.Procs, _ = strconv.Atoi(matches[2])
+	}
+	b.N, _ = strconv.Atoi(matches[3])
+	fields := strings.Fields(matches[4])
+	for i := 0; i+1 < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil
+		}
+		switch unit := fields[i+1]; unit {
+		case "ns/op":
+			b.NsPerOp = value
+		case "B/op":
+			b.BytesPerOp, b.Mem = value, true
+		case "allocs/op":
+			b.AllocsPerOp, b.Mem = value, true
+		case "MB/s":
+			b.MBPerSec = value
+		default:
+			b.Metrics = append(b.Metrics, BenchMetric{Unit: unit, Value: value})
+		}
+	}
+	return b
+}
+
+// addBenchLine add the benchmark result to package if the line is the output of benchmark,
+// return false if the line is not the output of benchmark.
+func (pkg *Package) addBenchLine(line string) bool {
+	if b := parseBenchmark(line); b != nil {
+		pkg.Benchmarks = append(pkg.Benchmarks, b)
+		return true
+	}
+	return regBenchInfo.MatchString(strings.TrimSpace(line))
+}
+
+// benchProperties convert the benchmark results to junit properties, the property name look like:
+//
+//	benchmark.BenchmarkFoo.ns/op
+//
+// the benchmark run with '-count N' has N properties of same name.
+func benchProperties(list []*Benchmark) []formater.JUnitProperty {
+	var props []formater.JUnitProperty
+	add := func(b *Benchmark, unit string, value float64) {
+		props = append(props, formater.JUnitProperty{
+			Name:  "benchmark." + b.Name + "." + unit,
+			Value: formatFloat(value),
+		})
+	}
+	for _, b := range list {
+		add(b, "ns/op", b.NsPerOp)
+		if b.Mem {
+			add(b, "B/op", b.BytesPerOp)
+			add(b, "allocs/op", b.AllocsPerOp)
+		}
+		if b.MBPerSec > 0 {
+			add(b, "MB/s", b.MBPerSec)
+		}
+		for _, m := range b.Metrics {
+			add(b, m.Unit, m.Value)
+		}
+	}
+	return props
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
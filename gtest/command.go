@@ -16,16 +16,25 @@
 package gtest
 
 import (
-	"bufio"
 	"bytes"
+	gocontext "context"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/coverage"
+	"github.com/ysqi/gcodesharp/reporter"
 )
 
 type errHander func(fm string, args ...interface{})
@@ -37,119 +46,318 @@ type Report struct {
 		OS        string
 		Arch      string
 	}
-	Creted   time.Time
+	Created  time.Time
 	Cost     float32
 	Packages []*Package
+	SysErr   error `json:"-"` // the error of go test, see JError
 }
 
-// Config run go test config
+// Config run go test config, it is the "gtest" section of config file.
 type Config struct {
-	PackagePaths  []string //need run go test for some dir
-	ContainImport bool     //need run all for child dir
+	Tags    []string `yaml:"tags"`    // build tags, e.g: [integration]
+	Timeout string   `yaml:"timeout"` // test timeout, e.g: 30s
+	Race    bool     `yaml:"race"`    // enable data race detection
+	Cover   bool     `yaml:"cover"`   // enable coverage analysis, default is true
+	// CoverPkg apply coverage analysis in each test to packages matching the patterns,
+	// e.g: [./...], the default is the package being tested.
+	CoverPkg []string `yaml:"coverpkg"`
+	// Bench run the benchmarks matching the regexp with -benchmem, e.g: "." run all benchmarks.
+	// the benchmark is disabled if it is empty.
+	Bench     string `yaml:"bench"`
+	BenchTime string `yaml:"benchtime"` // the time or iterations of each benchmark, e.g: 1s, 100x
+	// Rerun the max times to rerun a failed top-level test in its package,
+	// the test is FLAKY if it passed on rerun. zero means not rerun.
+	Rerun int      `yaml:"rerun"`
+	Args  []string `yaml:"args"` // more args of go test, e.g: [-count=1]
+}
+
+// args return the args of go test.
+func (c Config) args() []string {
+	args := []string{"-v"}
+	if c.Cover {
+		args = append(args, "-cover")
+		if len(c.CoverPkg) > 0 {
+			args = append(args, "-coverpkg", strings.Join(c.CoverPkg, ","))
+		}
+	}
+	args = c.buildArgs(args)
+	if c.Bench != "" {
+		args = append(args, "-bench", c.Bench, "-benchmem")
+		if c.BenchTime != "" {
+			args = append(args, "-benchtime", c.BenchTime)
+		}
+	}
+	return append(args, c.Args...)
+}
+
+// rerunArgs return the args of go test to rerun the top-level test only,
+// the coverage and benchmark are disabled, and the test result is not cached.
+func (c Config) rerunArgs(name string) []string {
+	args := c.buildArgs([]string{"-v"})
+	args = append(args, c.Args...)
+	return append(args, "-run", "^"+regexp.QuoteMeta(name)+"$", "-count=1")
+}
+
+// buildArgs append the args which are used by both run and rerun.
+func (c Config) buildArgs(args []string) []string {
+	if c.Race {
+		args = append(args, "-race")
+	}
+	if len(c.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(c.Tags, ","))
+	}
+	if c.Timeout != "" {
+		args = append(args, "-timeout", c.Timeout)
+	}
+	return args
 }
 
 type Service struct {
 	Report
+	Config Config
+	// Scheduler runs the go test of each package, all of them run at once if nil.
+	Scheduler *reporter.Scheduler
+	// Events streams the progress of each package, the events are dropped if nil.
+	Events *reporter.EventBus
 
-	ctx *context.Context
+	ctx  *context.Context
+	jobs *reporter.Jobs
+	// the packages which can not be compiled, see Consume
+	buildErrors reporter.BuildErrors
 
-	errh      errHander
-	completed chan struct{}
-	exit      chan struct{}
+	errh errHander
 }
 
 func New(ctx *context.Context, errh errHander) (*Service, error) {
 	return &Service{
-		ctx:       ctx,
-		errh:      errh,
-		completed: make(chan struct{}, 1),
-		exit:      make(chan struct{}, 3),
+		Config: Config{
+			Cover: true,
+		},
+		ctx:  ctx,
+		errh: errh,
 	}, nil
 }
 
-func (s *Service) error(msg string) {
-	s.errh("gtest: %s", msg)
-	s.Stop()
-}
-
-func (s *Service) Run() error {
-
-	s.Report.Creted = time.Now()
+// Run go test and block until done, the go test processes and test binaries are killed if ctx is done,
+// the package which is killed is failed and its finished tests are kept.
+func (s *Service) Run(ctx gocontext.Context) error {
+	s.Report.Created = time.Now()
 	s.Report.Env.GoVersion = runtime.Version()
 	s.Report.Env.OS = runtime.GOOS
 	s.Report.Env.Arch = runtime.GOARCH
 
-	go func() {
-		wg := sync.WaitGroup{}
-		wg.Add(len(s.ctx.Packages))
-		for _, p := range s.ctx.Packages {
-
-			// batch gofmt
-			go func(path string) {
-				defer wg.Done()
-				select {
-				default:
-				case <-s.exit:
-					return
-				}
-				pkg, err := run(path, []string{"-cover", "-v"})
-				if err != nil {
-					s.error(err.Error())
-					return
-				}
-				s.Report.Packages = append(s.Report.Packages, pkg)
-
-			}(p.ImportPath)
-
-			//abort the foreach if exit
-			select {
-			case <-s.exit:
-				break
-			default:
+	s.jobs = reporter.NewJobs(ctx, "gtest", s.Scheduler, s.Events, s.errh)
+	ctx = s.jobs.Context()
+	results := make([]*Package, len(s.ctx.Packages))
+	for i, p := range s.ctx.Packages {
+		if p.HasLoadError() {
+			// the package can not be built, report the load error instead of run go test.
+			results[i] = loadFailed(p)
+			s.jobs.Skip(p.ImportPath, true, nil)
+			continue
+		}
+		if _, ok := s.buildErrors[p.ImportPath]; ok {
+			// the compile error is reported by gbuild, so go test is not run.
+			results[i] = buildFailed(p)
+			s.jobs.Skip(p.ImportPath, true, nil)
+			continue
+		}
+
+		// batch go test
+		i, dir, path := i, p.Dir, p.ImportPath
+		s.jobs.Go(path, func() (bool, []reporter.Finding) {
+			results[i] = s.test(ctx, dir, path)
+			if results[i] == nil {
+				return false, nil
 			}
+			s.publishTests(path, results[i])
+			return results[i].Failed, nil
+		})
+	}
+	// wait for all go test done
+	s.SysErr = s.jobs.Wait()
+	for _, pkg := range results {
+		// nil if the package is not tested, e.g: canceled before it started.
+		if pkg != nil {
+			s.Report.Packages = append(s.Report.Packages, pkg)
 		}
-		go func() {
-			// wait for all go test  done
-			wg.Wait()
-			s.Report.Cost = float32(time.Since(s.Report.Creted).Seconds())
-			close(s.completed)
-		}()
-	}()
-	return nil
+	}
+	sort.SliceStable(s.Report.Packages, func(i, j int) bool {
+		return s.Report.Packages[i].Name < s.Report.Packages[j].Name
+	})
+	s.Report.Cost = float32(time.Since(s.Report.Created).Seconds())
+	return s.SysErr
 }
 
-func (s *Service) Stop() error {
-	close(s.exit)
-	return nil
+// test run go test for the package, return nil if it is not run.
+func (s *Service) test(ctx gocontext.Context, dir, path string) *Package {
+	if ctx.Err() != nil {
+		return nil
+	}
+	args := s.Config.args()
+	if jsonSupported(s.ctx.GoVersion) {
+		args = append(args, "-json")
+	}
+	var profile string
+	if s.Config.Cover {
+		// save the cover profile to temp file, and parse it after test done.
+		f, err := ioutil.TempFile("", "gcodesharp-cover")
+		if err != nil {
+			s.jobs.Error(err.Error())
+			return nil
+		}
+		f.Close()
+		profile = f.Name()
+		defer os.Remove(profile)
+		args = append(args, "-coverprofile", profile)
+	}
+	pkg, err := run(ctx, dir, path, args, s.jobs.Output(path))
+	if err != nil {
+		if ctx.Err() == nil {
+			s.jobs.Error(err.Error())
+		}
+		return nil
+	}
+	pkg.Dir = dir
+	if err := ctx.Err(); err != nil {
+		// killed, keep the finished tests.
+		pkg.cancel("go test is canceled:" + err.Error())
+		return pkg
+	}
+	if profile != "" {
+		if pkg.Profiles, err = s.loadProfiles(profile); err != nil {
+			s.jobs.Warn(path, "load cover profile of %s:%s", path, err)
+		} else if len(pkg.Profiles) > 0 {
+			pkg.setCovers(coverByPackage(pkg.Profiles))
+		}
+	}
+	if s.Config.Rerun > 0 {
+		s.rerun(ctx, dir, path, pkg)
+	}
+	return pkg
 }
 
-func (s *Service) Wait() error {
-	for {
-		select {
-		case <-s.exit:
-			return nil
-		case <-s.completed:
-			return nil
-		case <-time.After(1 * time.Second):
+// publishTests publish the result of each test of package.
+func (s *Service) publishTests(path string, pkg *Package) {
+	for _, u := range pkg.AllUnits() {
+		switch u.Result {
+		case PASS, FLAKY:
+			s.jobs.Publish(reporter.Event{Kind: reporter.EventTestPassed, Package: path, Test: u.Name})
+		case FAIL:
+			s.jobs.Publish(reporter.Event{Kind: reporter.EventTestFailed, Package: path, Test: u.Name, Output: u.Output})
 		}
 	}
 }
 
-func run(packagepath string, args []string) (pkg *Package, err error) {
-	var (
-		stderr bytes.Buffer
-		stdout io.ReadCloser
-	)
-	// TODO: need support more args
-	cmd := exec.Command("go", "test", packagepath)
-	cmd.Args = append(cmd.Args, args...)
+// rerun the failed top-level tests of package one by one until it passed or rerun Config.Rerun times,
+// the test passed on rerun is marked FLAKY, and the package is passed if no test failed after rerun.
+func (s *Service) rerun(ctx gocontext.Context, dir, path string, pkg *Package) {
+	if pkg.FailCount() == 0 {
+		return
+	}
+	for _, u := range pkg.Units {
+		if u.Result != FAIL {
+			continue
+		}
+		for i := 0; i < s.Config.Rerun; i++ {
+			args := s.Config.rerunArgs(u.Name)
+			if jsonSupported(s.ctx.GoVersion) {
+				args = append(args, "-json")
+			}
+			rp, err := run(ctx, dir, path, args, s.jobs.Output(path))
+			if ctx.Err() != nil {
+				// the result of killed rerun is dropped.
+				return
+			}
+			if err != nil {
+				s.jobs.Warn(path, "rerun %s of %s:%s", u.Name, path, err)
+				break
+			}
+			retry := findUnitTest(rp.Units, u.Name)
+			if retry == nil {
+				// e.g: the test panic before it started
+				retry = &Unit{Name: u.Name, Runtime: rp.Runtime, Result: FAIL, Output: rp.Err}
+			}
+			u.Retries = append(u.Retries, retry)
+			s.jobs.Log(path, "rerun %s of %s %d/%d:%s", u.Name, path, i+1, s.Config.Rerun, retry.Result)
+			if retry.Result == PASS {
+				u.markFlaky()
+				break
+			}
+		}
+	}
+	if pkg.FailCount() == 0 {
+		pkg.Failed = false
+	}
+}
 
-	cmd.Stderr = &stderr
-	stdout, err = cmd.StdoutPipe()
+// loadProfiles parse the cover profile file, and resolve the file path on disk by the package dir.
+// the empty profile file is ignored, e.g: the package build failed.
+func (s *Service) loadProfiles(file string) ([]*coverage.Profile, error) {
+	f, err := os.Open(file)
 	if err != nil {
 		return nil, err
 	}
-	scanner := bufio.NewScanner(stdout)
+	defer f.Close()
+	profiles, err := coverage.ParseProfiles(f)
+	if err != nil {
+		return nil, err
+	}
+	dirs := map[string]string{}
+	for _, p := range s.ctx.Packages {
+		dirs[p.ImportPath] = p.Dir
+	}
+	for _, p := range profiles {
+		if filepath.IsAbs(p.FileName) {
+			p.Path = p.FileName
+			continue
+		}
+		importPath, name := path.Split(p.FileName)
+		if dir, ok := dirs[strings.TrimSuffix(importPath, "/")]; ok {
+			p.Path = filepath.Join(dir, name)
+		}
+	}
+	return profiles, nil
+}
+
+// loadFailed return a failed package result for the package which can not be loaded.
+func loadFailed(p *context.Package) *Package {
+	return &Package{
+		Name:     p.ImportPath,
+		Dir:      p.Dir,
+		Runtime:  time.Now(),
+		Failed:   true,
+		Coverage: -1,
+		Err:      p.LoadError(),
+	}
+}
+
+// buildFailed return the failed package which can not be compiled,
+// the compile error is not kept, so it is reported once by gbuild.
+func buildFailed(p *context.Package) *Package {
+	return &Package{
+		Name:     p.ImportPath,
+		Dir:      p.Dir,
+		Runtime:  time.Now(),
+		Failed:   true,
+		Coverage: -1,
+		Err:      "build failed, see the report of gbuild",
+	}
+}
+
+// run go test for the package in dir,
+// the go command works in the dir to find the right module.
+// the go test is killed if ctx is done, and the output before killed is parsed.
+// the output is called with each line of test output if it is not nil.
+func run(ctx gocontext.Context, dir, packagepath string, args []string, output func(line string)) (pkg *Package, err error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", "test", packagepath)
+	cmd.Args = append(cmd.Args, args...)
+	cmd.Dir = dir
+
+	cmd.Stderr = &stderr
+	stdout, writer := io.Pipe()
+	cmd.Stdout = writer
 
 	pkg = &Package{
 		Name: packagepath,
@@ -159,7 +367,8 @@ func run(packagepath string, args []string) (pkg *Package, err error) {
 	wg.Add(1)
 	setLastErr := func(err interface{}) {
 		pkg.Failed = true
-		if len(pkg.Units) == 0 {
+		units := pkg.AllUnits()
+		if len(units) == 0 {
 			return
 		}
 		errStr := ""
@@ -171,8 +380,9 @@ func run(packagepath string, args []string) (pkg *Package, err error) {
 		default:
 			errStr = fmt.Sprintf("%v", v)
 		}
-		pkg.Units[len(pkg.Units)-1].Output = errStr
-		pkg.Units[len(pkg.Units)-1].Result = FAIL
+		units[len(units)-1].Output = errStr
+		units[len(units)-1].Result = FAIL
+		pkg.aggregate()
 	}
 	defer func() {
 		if err := recover(); err != nil {
@@ -181,19 +391,26 @@ func run(packagepath string, args []string) (pkg *Package, err error) {
 	}()
 	go func() {
 		var pkgs []*Package
-		pkgs, err = parse(scanner, true)
-		if err == nil && len(pkgs) > 0 {
+		pkgs, err = parseOutput(stdout, output)
+		// the output may contain other packages, e.g: the dependency which failed to build.
+		if p := findPkg(pkgs, packagepath); err == nil && p != nil {
+			pkg = p
+		} else if err == nil && len(pkgs) > 0 {
 			pkg = pkgs[0]
 		}
+		// drain the output if the parser stopped, so the go test is not blocked.
+		io.Copy(ioutil.Discard, stdout)
 		wg.Done()
 	}()
 
-	if err := cmd.Start(); err != nil {
-		return nil, err
-	}
-
+	runErr := context.RunCommand(ctx, cmd)
+	writer.Close()
 	wg.Wait()
-	if err = cmd.Wait(); err != nil {
+	if runErr != nil {
+		if cmd.Process == nil {
+			// not started
+			return nil, runErr
+		}
 		errStr := stderr.String()
 		pkg.Failed = true
 		if pkg != nil {
@@ -204,8 +421,8 @@ func run(packagepath string, args []string) (pkg *Package, err error) {
 			}
 			errStr = ""
 		}
-		if _, ok := err.(*exec.ExitError); !ok {
-			errStr = appendLine(errStr, err.Error())
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			errStr = appendLine(errStr, runErr.Error())
 		}
 		pkg.Err = appendLine(pkg.Err, errStr)
 	}
@@ -16,11 +16,12 @@
 package gtest
 
 import (
-	"go/build"
+	gocontext "context"
 	"strings"
 	"testing"
 
 	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/reporter"
 )
 
 func TestRelTime(t *testing.T) {
@@ -29,11 +30,11 @@ func TestRelTime(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	p, err := build.Import("github.com/ysqi/gcodesharp/gtest/testdata", "", build.IgnoreVendor)
+	pkgs, err := context.ListPackages("", "./testdata")
 	if err != nil {
 		t.Fatal(err)
 	}
-	ctx.Packages = append(ctx.Packages, p)
+	ctx.Packages = append(ctx.Packages, pkgs...)
 
 	ser, err := New(ctx, func(fm string, args ...interface{}) {
 		t.Fatalf(fm, args...)
@@ -41,11 +42,7 @@ func TestRelTime(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = ser.Run()
-	if err != nil {
-		t.Fatal(err)
-	}
-	err = ser.Wait()
+	err = ser.Run(gocontext.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -54,7 +51,7 @@ func TestRelTime(t *testing.T) {
 		t.Fatal("want one pakcage rest report,but zero")
 	}
 	pkg := ser.Report.Packages[0]
-	for _, u := range pkg.Units {
+	for _, u := range pkg.AllUnits() {
 		t.Logf("%+v", u)
 	}
 	if !pkg.Failed {
@@ -84,3 +81,43 @@ func TestRelTime(t *testing.T) {
 		t.Fatalf("want the number of skip test is %d,but got %d", 1, skip)
 	}
 }
+
+func TestBuildFailed(t *testing.T) {
+	ctx, err := context.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err := context.ListPackages("", "./testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.Packages = append(ctx.Packages, pkgs...)
+	ser, err := New(ctx, func(fm string, args ...interface{}) {
+		t.Fatalf(fm, args...)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := reporter.NewArtifacts()
+	if err := ser.Consume(a); err != nil {
+		t.Fatalf("want no error if gbuild is not run, got %s", err)
+	}
+	a.Put(reporter.ArtifactBuildErrors, reporter.BuildErrors{pkgs[0].ImportPath: "undefined: x"})
+	if err := ser.Consume(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := ser.Run(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	pkg := ser.Report.Packages[0]
+	if !pkg.Failed || len(pkg.Units) != 0 || !strings.Contains(pkg.Err, "gbuild") || strings.Contains(pkg.Err, "undefined: x") {
+		t.Fatalf("want the package is failed without test, got %+v", pkg)
+	}
+}
+
+func TestConfigBuildArgs(t *testing.T) {
+	c := Config{Tags: []string{"integration", "linux"}, Timeout: "30s"}
+	if got := strings.Join(c.rerunArgs("TestFoo"), " "); got != "-v -tags integration,linux -timeout 30s -run ^TestFoo$ -count=1" {
+		t.Fatalf("want the tags joined by comma, got %q", got)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"path"
+	"sort"
+
+	"github.com/ysqi/gcodesharp/coverage"
+)
+
+// CoverProfiles return the merged cover profiles of all packages.
+func (r *Report) CoverProfiles() []*coverage.Profile {
+	list := make([][]*coverage.Profile, 0, len(r.Packages))
+	for _, pkg := range r.Packages {
+		list = append(list, pkg.Profiles)
+	}
+	return coverage.Merge(list...)
+}
+
+// TotalCoverage return the coverage of all packages, which is calculated from the merged profiles,
+// so the statement covered by the tests of more than one package is counted once.
+// return -1 if no cover profile.
+func (r *Report) TotalCoverage() float32 {
+	profiles := r.CoverProfiles()
+	if len(profiles) == 0 {
+		return -1
+	}
+	return float32(coverage.TotalPercent(profiles))
+}
+
+// setCovers replace the coverage of target packages, and set the coverage of package itself
+// if it is one of the targets.
+func (pkg *Package) setCovers(covers []Cover) {
+	pkg.Covers = covers
+	for _, c := range covers {
+		if c.Target == pkg.Name {
+			pkg.Coverage = c.Percent
+		}
+	}
+}
+
+// coverByPackage return the coverage of each package in profiles, sorted by import path.
+// the file name of profile is prefixed with the import path, e.g: github.com/ysqi/com/com.go
+func coverByPackage(profiles []*coverage.Profile) []Cover {
+	groups := map[string][]*coverage.Profile{}
+	for _, p := range profiles {
+		target := path.Dir(p.FileName)
+		groups[target] = append(groups[target], p)
+	}
+	covers := make([]Cover, 0, len(groups))
+	for target, list := range groups {
+		covers = append(covers, Cover{
+			Target:  target,
+			Percent: float32(coverage.TotalPercent(list)),
+		})
+	}
+	sort.Slice(covers, func(i, j int) bool { return covers[i].Target < covers[j].Target })
+	return covers
+}
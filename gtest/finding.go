@@ -0,0 +1,85 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// ToolName the tool name of finding.
+const ToolName = "gotest"
+
+// regTestPos match the first position of test output, e.g: a_test.go:12: message
+var regTestPos = regexp.MustCompile(`(?m)^\s*([^\s:]+\.go):(\d+):`)
+
+// Tool return the tool name of findings.
+func (r *Report) Tool() string {
+	return ToolName
+}
+
+// Findings return a finding for each failed package which has error info,
+// and each failed test which has no failed subtest.
+// the position of failed test is the first position in the test output.
+func (r *Report) Findings() []reporter.Finding {
+	var list []reporter.Finding
+	for _, pkg := range r.Packages {
+		if pkg.Failed && pkg.Err != "" {
+			list = append(list, reporter.Finding{
+				Tool:     ToolName,
+				Rule:     "build",
+				Severity: reporter.SeverityError,
+				Message:  pkg.Name + ": " + strings.TrimSpace(pkg.Err),
+			})
+		}
+		for _, u := range pkg.GetByResult(FAIL) {
+			if hasFailedChild(u) {
+				continue
+			}
+			f := reporter.Finding{
+				Tool:     ToolName,
+				Rule:     u.Name,
+				Severity: reporter.SeverityError,
+				Message:  pkg.Name + "." + u.Name + " failed",
+			}
+			if out := strings.TrimSpace(u.Output); out != "" {
+				f.Message += ": " + out
+			}
+			if matches := regTestPos.FindStringSubmatch(u.Output); len(matches) == 3 {
+				f.File = matches[1]
+				if pkg.Dir != "" && !filepath.IsAbs(f.File) {
+					f.File = filepath.Join(pkg.Dir, f.File)
+				}
+				f.Line, _ = strconv.Atoi(matches[2])
+			}
+			list = append(list, f)
+		}
+	}
+	return list
+}
+
+func hasFailedChild(u *Unit) bool {
+	for _, c := range u.Children {
+		if c.Result == FAIL {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,160 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+// HTitle return the title of go test html report.
+func (r *Report) HTitle() string {
+	return "go test"
+}
+
+// HSummary return the test count of each result and the total coverage,
+// it is the average coverage of packages if no cover profile.
+func (r *Report) HSummary() string {
+	var pass, fail, skip, flaky, failedPkg, covered, benchs int
+	var coverage float32
+	for _, pkg := range r.Packages {
+		pass += pkg.PassCount()
+		fail += pkg.FailCount()
+		skip += pkg.SkipCount()
+		flaky += pkg.FlakyCount()
+		if pkg.Failed {
+			failedPkg++
+		}
+		benchs += len(pkg.Benchmarks)
+		if pkg.HasCoverage() {
+			covered++
+			coverage += pkg.Coverage
+		}
+	}
+	pkgLevel := formater.HTMLLevelPass
+	if failedPkg > 0 {
+		pkgLevel = formater.HTMLLevelFail
+	}
+	s := formater.HTMLStat("packages", len(r.Packages), formater.HTMLLevelInfo) +
+		formater.HTMLStat("failed packages", failedPkg, pkgLevel) +
+		formater.HTMLStat("pass", pass, formater.HTMLLevelPass) +
+		formater.HTMLStat("fail", fail, levelOf(FAIL, fail)) +
+		formater.HTMLStat("flaky", flaky, levelOf(FLAKY, flaky)) +
+		formater.HTMLStat("skip", skip, formater.HTMLLevelSkip) +
+		formater.HTMLStat("seconds", fmt.Sprintf("%.2f", r.Cost), formater.HTMLLevelInfo)
+	if benchs > 0 {
+		s += formater.HTMLStat("benchmarks", benchs, formater.HTMLLevelInfo)
+	}
+	if total := r.TotalCoverage(); total >= 0 {
+		s += formater.HTMLStat("total coverage", template.HTML(formater.HTMLCoverage(total)), formater.HTMLLevelInfo)
+	} else if covered > 0 {
+		s += formater.HTMLStat("average coverage", template.HTML(formater.HTMLCoverage(coverage/float32(covered))), formater.HTMLLevelInfo)
+	}
+	return s
+}
+
+// HGroupDetail return the test result of each package.
+func (r *Report) HGroupDetail() []string {
+	var groups []string
+	for _, pkg := range r.Packages {
+		level := formater.HTMLLevelPass
+		if pkg.Failed {
+			level = formater.HTMLLevelFail
+		} else if pkg.FlakyCount() > 0 {
+			level = formater.HTMLLevelWarn
+		}
+		title := fmt.Sprintf("%s  pass:%d fail:%d flaky:%d skip:%d  %.3fs",
+			pkg.Name, pkg.PassCount(), pkg.FailCount(), pkg.FlakyCount(), pkg.SkipCount(), pkg.Cost)
+
+		body := formater.HTMLCoverage(pkg.Coverage)
+		if covers := pkg.OtherCovers(); len(covers) > 0 {
+			rows := make([]formater.HTMLRow, 0, len(covers))
+			for _, c := range covers {
+				rows = append(rows, formater.HTMLRow{
+					Level: formater.HTMLLevelInfo,
+					Cells: []interface{}{c.Target, template.HTML(formater.HTMLCoverage(c.Percent))},
+				})
+			}
+			body += formater.HTMLTable([]string{"covered package", "coverage"}, rows)
+		}
+		if pkg.Err != "" {
+			body += formater.HTMLPre(pkg.Err)
+		}
+		if units := pkg.AllUnits(); len(units) > 0 {
+			rows := make([]formater.HTMLRow, 0, len(units))
+			for _, u := range units {
+				var output interface{} = ""
+				if u.Output != "" && u.Result != PASS {
+					output = template.HTML(formater.HTMLPre(u.Output))
+				}
+				// indent the subtest under its parent
+				name := template.HTML(strings.Repeat("&emsp;", u.Depth()) + template.HTMLEscapeString(u.SubName()))
+				rows = append(rows, formater.HTMLRow{
+					Level: levelOf(u.Result, 1),
+					Cells: []interface{}{u.Result.String(), name, fmt.Sprintf("%.3fs", u.Cost), output},
+				})
+			}
+			body += formater.HTMLTable([]string{"result", "test", "time", "output"}, rows)
+		}
+		if len(pkg.Benchmarks) > 0 {
+			body += benchTable(pkg.Benchmarks)
+		}
+		groups = append(groups, formater.HTMLDetails(title, body, level, pkg.Failed))
+	}
+	return groups
+}
+
+// benchTable render the benchmark results as a table, the empty cell if the metric is not reported.
+func benchTable(list []*Benchmark) string {
+	rows := make([]formater.HTMLRow, 0, len(list))
+	for _, b := range list {
+		var mem [2]string
+		if b.Mem {
+			mem[0], mem[1] = formatFloat(b.BytesPerOp), formatFloat(b.AllocsPerOp)
+		}
+		var metrics []string
+		if b.MBPerSec > 0 {
+			metrics = append(metrics, formatFloat(b.MBPerSec)+" MB/s")
+		}
+		for _, m := range b.Metrics {
+			metrics = append(metrics, formatFloat(m.Value)+" "+m.Unit)
+		}
+		rows = append(rows, formater.HTMLRow{
+			Level: formater.HTMLLevelInfo,
+			Cells: []interface{}{b.Name, b.N, formatFloat(b.NsPerOp), mem[0], mem[1], strings.Join(metrics, ", ")},
+		})
+	}
+	return formater.HTMLTable([]string{"benchmark", "iterations", "ns/op", "B/op", "allocs/op", "metrics"}, rows)
+}
+
+// levelOf return the html level of test result, pass level if the count is zero.
+func levelOf(r Result, count int) string {
+	if count == 0 {
+		return formater.HTMLLevelPass
+	}
+	switch r {
+	case FAIL:
+		return formater.HTMLLevelFail
+	case SKIP:
+		return formater.HTMLLevelSkip
+	case FLAKY:
+		return formater.HTMLLevelWarn
+	}
+	return formater.HTMLLevelPass
+}
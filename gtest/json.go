@@ -0,0 +1,214 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ysqi/gcodesharp/coverage"
+)
+
+// JSONReport the json schema of go test report, see reporter.JSONService.Decode.
+type JSONReport struct {
+	Created  time.Time      `json:"created"`
+	Cost     float32        `json:"cost"` // the seconds of go test run
+	Packages []*JSONPackage `json:"packages"`
+}
+
+// JSONPackage the json schema of a tested package.
+type JSONPackage struct {
+	Name       string           `json:"name"`
+	Dir        string           `json:"dir,omitempty"`
+	Cost       float32          `json:"cost"`
+	Runtime    time.Time        `json:"runtime"`
+	Coverage   float32          `json:"coverage"` // -1 if unknown
+	Covers     []JSONCover      `json:"covers,omitempty"`
+	Profiles   []*JSONProfile   `json:"profiles,omitempty"`
+	Failed     bool             `json:"failed"`
+	Error      string           `json:"error,omitempty"`
+	Tests      []*JSONTest      `json:"tests"`
+	Benchmarks []*JSONBenchmark `json:"benchmarks,omitempty"`
+}
+
+// JSONCover the json schema of the coverage of a target package.
+type JSONCover struct {
+	Target  string  `json:"target"`
+	Percent float32 `json:"percent"`
+}
+
+// JSONProfile the json schema of the cover profile of a file.
+type JSONProfile struct {
+	FileName string      `json:"file_name"`
+	Path     string      `json:"path,omitempty"`
+	Mode     string      `json:"mode"`
+	Blocks   []JSONBlock `json:"blocks"`
+}
+
+// JSONBlock the json schema of a code block of cover profile.
+type JSONBlock struct {
+	StartLine int `json:"start_line"`
+	StartCol  int `json:"start_col"`
+	EndLine   int `json:"end_line"`
+	EndCol    int `json:"end_col"`
+	NumStmt   int `json:"num_stmt"`
+	Count     int `json:"count"`
+}
+
+// JSONTest the json schema of a test, the subtests are nested in its parent.
+type JSONTest struct {
+	Name     string      `json:"name"`
+	Cost     float32     `json:"cost"`
+	Runtime  time.Time   `json:"runtime"`
+	Result   Result      `json:"result"` // the name of result, e.g: PASS
+	Output   string      `json:"output,omitempty"`
+	Subtests []*JSONTest `json:"subtests,omitempty"`
+	Retries  []*JSONTest `json:"retries,omitempty"`
+}
+
+// JSONBenchmark the json schema of a benchmark result.
+type JSONBenchmark struct {
+	Name        string       `json:"name"`
+	Procs       int          `json:"procs"`
+	N           int          `json:"n"`
+	NsPerOp     float64      `json:"ns_per_op"`
+	BytesPerOp  float64      `json:"bytes_per_op,omitempty"`
+	AllocsPerOp float64      `json:"allocs_per_op,omitempty"`
+	MBPerSec    float64      `json:"mb_per_sec,omitempty"`
+	Mem         bool         `json:"mem"`
+	Metrics     []JSONMetric `json:"metrics,omitempty"`
+}
+
+// JSONMetric the json schema of a custom benchmark metric.
+type JSONMetric struct {
+	Unit  string  `json:"unit"`
+	Value float64 `json:"value"`
+}
+
+// JName return the name of report in json.
+func (r *Report) JName() string {
+	return "gtest"
+}
+
+// JReport return the report which is encoded to json, it is decoded by JSONReport.
+func (r *Report) JReport() interface{} {
+	report := &JSONReport{
+		Created:  r.Created,
+		Cost:     r.Cost,
+		Packages: []*JSONPackage{},
+	}
+	for _, p := range r.Packages {
+		report.Packages = append(report.Packages, jsonPackage(p))
+	}
+	return report
+}
+
+// JError return the error of go test.
+func (r *Report) JError() error {
+	return r.SysErr
+}
+
+func jsonPackage(p *Package) *JSONPackage {
+	jp := &JSONPackage{
+		Name:     p.Name,
+		Dir:      p.Dir,
+		Cost:     p.Cost,
+		Runtime:  p.Runtime,
+		Coverage: p.Coverage,
+		Failed:   p.Failed,
+		Error:    p.Err,
+		Tests:    jsonTests(p.Units),
+	}
+	if jp.Tests == nil {
+		jp.Tests = []*JSONTest{}
+	}
+	for _, c := range p.Covers {
+		jp.Covers = append(jp.Covers, JSONCover{Target: c.Target, Percent: c.Percent})
+	}
+	for _, prof := range p.Profiles {
+		jp.Profiles = append(jp.Profiles, jsonProfile(prof))
+	}
+	for _, b := range p.Benchmarks {
+		jb := &JSONBenchmark{
+			Name:        b.Name,
+			Procs:       b.Procs,
+			N:           b.N,
+			NsPerOp:     b.NsPerOp,
+			BytesPerOp:  b.BytesPerOp,
+			AllocsPerOp: b.AllocsPerOp,
+			MBPerSec:    b.MBPerSec,
+			Mem:         b.Mem,
+		}
+		for _, m := range b.Metrics {
+			jb.Metrics = append(jb.Metrics, JSONMetric{Unit: m.Unit, Value: m.Value})
+		}
+		jp.Benchmarks = append(jp.Benchmarks, jb)
+	}
+	return jp
+}
+
+func jsonProfile(p *coverage.Profile) *JSONProfile {
+	jp := &JSONProfile{
+		FileName: p.FileName,
+		Path:     p.Path,
+		Mode:     p.Mode,
+		Blocks:   make([]JSONBlock, 0, len(p.Blocks)),
+	}
+	for _, b := range p.Blocks {
+		jp.Blocks = append(jp.Blocks, JSONBlock{
+			StartLine: b.StartLine,
+			StartCol:  b.StartCol,
+			EndLine:   b.EndLine,
+			EndCol:    b.EndCol,
+			NumStmt:   b.NumStmt,
+			Count:     b.Count,
+		})
+	}
+	return jp
+}
+
+// jsonTests convert the tests and its subtests, it return nil if no test.
+func jsonTests(units []*Unit) []*JSONTest {
+	var list []*JSONTest
+	for _, u := range units {
+		list = append(list, &JSONTest{
+			Name:     u.Name,
+			Cost:     u.Cost,
+			Runtime:  u.Runtime,
+			Result:   u.Result,
+			Output:   u.Output,
+			Subtests: jsonTests(u.Children),
+			Retries:  jsonTests(u.Retries),
+		})
+	}
+	return list
+}
+
+// MarshalText encode the result as its name, e.g: PASS
+func (r Result) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText decode the result from its name.
+func (r *Result) UnmarshalText(text []byte) error {
+	for v := PASS; v <= FLAKY; v++ {
+		if v.String() == string(text) {
+			*r = v
+			return nil
+		}
+	}
+	return fmt.Errorf("can't parse %q to Result enum", text)
+}
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"io"
 	"strings"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
 )
 
 // Note: change from https://github.com/jstemmer/go-junit-report/blob/master/junit-formatter.go
@@ -54,6 +56,10 @@ type JUnitTestCase struct {
 	Time        float32           `xml:"time,attr"`
 	SkipMessage *JUnitSkipMessage `xml:"skipped,omitempty"`
 	Failure     *JUnitFailure     `xml:"failure,omitempty"`
+	// FlakyFailures the failed runs of the test which passed on rerun.
+	FlakyFailures []formater.JUnitRerunFailure `xml:"flakyFailure,omitempty"`
+	// RerunFailures the failed reruns of the test which is still failed.
+	RerunFailures []formater.JUnitRerunFailure `xml:"rerunFailure,omitempty"`
 }
 
 // JUnitSkipMessage contains the reason why a testcase was skipped.
@@ -82,7 +88,7 @@ func JUnitReportXML(report *Report, noXMLHeader bool, w io.Writer) error {
 	// convert Report to JUnit test suites
 	for _, pkg := range report.Packages {
 		ts := JUnitTestSuite{
-			Tests:      len(pkg.Units),
+			Tests:      len(pkg.AllUnits()),
 			Time:       pkg.Cost,
 			Name:       pkg.Name,
 			Properties: []JUnitProperty{},
@@ -108,13 +114,17 @@ func JUnitReportXML(report *Report, noXMLHeader bool, w io.Writer) error {
 		}
 
 		// individual test cases
-		for _, test := range pkg.Units {
+		for _, test := range pkg.AllUnits() {
 			testCase := JUnitTestCase{
 				Classname: classname,
 				Name:      test.Name,
 				Time:      test.Cost,
 				Failure:   nil,
 			}
+			if test.Parent != nil {
+				testCase.Classname = test.Root().Name
+				testCase.Name = test.SubName()
+			}
 
 			if test.Result == FAIL {
 				testCase.Failure = &JUnitFailure{
@@ -122,6 +132,13 @@ func JUnitReportXML(report *Report, noXMLHeader bool, w io.Writer) error {
 					Type:     "",
 					Contents: test.Output,
 				}
+				testCase.RerunFailures = rerunFailures(test.Retries)
+			}
+
+			if test.Result == FLAKY {
+				// the first failed run and the failed reruns before passed.
+				testCase.FlakyFailures = append(rerunFailures([]*Unit{{Result: FAIL, Output: test.Output}}),
+					rerunFailures(test.Retries)...)
 			}
 
 			if test.Result == SKIP {
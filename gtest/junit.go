@@ -32,7 +32,7 @@ func (r *Report) ToJunit() (formater.JUnitTestSuites, error) {
 	for _, pkg := range r.Packages {
 
 		ts := formater.JUnitTestSuite{
-			Tests: len(pkg.Units),
+			Tests: len(pkg.AllUnits()),
 			Time:  pkg.Cost,
 			Name:  pkg.Name,
 			//Properties: []JUnitProperty{},
@@ -59,18 +59,31 @@ func (r *Report) ToJunit() (formater.JUnitTestSuites, error) {
 					Name:  "coverage.statements.pct",
 					Value: fmt.Sprintf("%.2f", pkg.Coverage)})
 		}
+		for _, c := range pkg.OtherCovers() {
+			ts.Properties = append(ts.Properties,
+				formater.JUnitProperty{
+					Name:  "coverage." + c.Target + ".statements.pct",
+					Value: fmt.Sprintf("%.2f", c.Percent)})
+		}
+		ts.Properties = append(ts.Properties, benchProperties(pkg.Benchmarks)...)
 		if pkg.Failed {
 			ts.Err = pkg.Err
 		}
 
 		// individual test cases
-		for _, test := range pkg.Units {
+		for _, test := range pkg.AllUnits() {
 			testCase := formater.JUnitTestCase{
 				Classname: classname,
 				Name:      test.Name,
 				Time:      test.Cost,
 				Failure:   nil,
 			}
+			// subtest use the top-level test as classname to show the hierarchy,
+			// e.g: TestFoo/case_1 => classname=TestFoo,name=case_1
+			if test.Parent != nil {
+				testCase.Classname = test.Root().Name
+				testCase.Name = test.SubName()
+			}
 
 			if test.Result == FAIL {
 				testCase.Failure = &formater.JUnitFailure{
@@ -78,6 +91,13 @@ func (r *Report) ToJunit() (formater.JUnitTestSuites, error) {
 					Type:     "",
 					Contents: test.Output,
 				}
+				testCase.RerunFailures = rerunFailures(test.Retries)
+			}
+
+			if test.Result == FLAKY {
+				// the first failed run and the failed reruns before passed.
+				testCase.FlakyFailures = append(rerunFailures([]*Unit{{Result: FAIL, Output: test.Output}}),
+					rerunFailures(test.Retries)...)
 			}
 
 			if test.Result == SKIP {
@@ -91,3 +111,18 @@ func (r *Report) ToJunit() (formater.JUnitTestSuites, error) {
 
 	return suites, nil
 }
+
+// rerunFailures convert the failed runs of test to junit rerun failures.
+func rerunFailures(runs []*Unit) []formater.JUnitRerunFailure {
+	var list []formater.JUnitRerunFailure
+	for _, u := range runs {
+		if u.Result != FAIL {
+			continue
+		}
+		list = append(list, formater.JUnitRerunFailure{
+			Message:    "Failed",
+			StackTrace: u.Output,
+		})
+	}
+	return list
+}
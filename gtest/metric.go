@@ -0,0 +1,89 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"sort"
+
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// Metrics return the failed count of tests and packages,
+// the coverage of each package which has coverage info and the total coverage.
+// the test count, duration and benchmark results are also returned for history.
+func (r *Report) Metrics() []reporter.Metric {
+	var failedTests, failedPkgs, skipped, flaky int
+	var metrics []reporter.Metric
+	for _, pkg := range r.Packages {
+		failedTests += pkg.FailCount()
+		skipped += pkg.SkipCount()
+		flaky += pkg.FlakyCount()
+		if pkg.Failed {
+			failedPkgs++
+		}
+		if pkg.HasCoverage() {
+			metrics = append(metrics, reporter.Metric{
+				Name:   reporter.MetricCoverage,
+				Target: pkg.Name,
+				Value:  float64(pkg.Coverage),
+			})
+		}
+		metrics = append(metrics,
+			reporter.Metric{Name: reporter.MetricTests, Target: pkg.Name, Value: float64(len(pkg.AllUnits()))},
+			reporter.Metric{Name: reporter.MetricDuration, Target: pkg.Name, Value: float64(pkg.Cost)},
+		)
+		metrics = append(metrics, benchMetrics(pkg)...)
+	}
+	if total := r.TotalCoverage(); total >= 0 {
+		metrics = append(metrics, reporter.Metric{
+			Name:  reporter.MetricTotalCoverage,
+			Value: float64(total),
+		})
+	}
+	return append([]reporter.Metric{
+		{Name: reporter.MetricFailedTests, Value: float64(failedTests)},
+		{Name: reporter.MetricFailedPackages, Value: float64(failedPkgs)},
+		{Name: reporter.MetricSkippedTests, Value: float64(skipped)},
+		{Name: reporter.MetricFlakyTests, Value: float64(flaky)},
+		{Name: reporter.MetricDuration, Value: float64(r.Cost)},
+	}, metrics...)
+}
+
+// benchMetrics return the mean of ns/op, B/op and allocs/op of each benchmark,
+// the target is the package and benchmark name, e.g: github.com/ysqi/com BenchmarkFoo-8
+func benchMetrics(pkg *Package) []reporter.Metric {
+	names := []string{reporter.MetricBenchNsPerOp, reporter.MetricBenchBytesPerOp, reporter.MetricBenchAllocsPerOp}
+	samples := benchSamples(pkg.Benchmarks)
+	list := make([]string, 0, len(samples))
+	for name := range samples {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	var metrics []reporter.Metric
+	for _, name := range list {
+		for i, values := range samples[name] {
+			if len(values) == 0 {
+				continue
+			}
+			metrics = append(metrics, reporter.Metric{
+				Name:   names[i],
+				Target: pkg.Name + " " + name,
+				Value:  stat(values).Mean,
+			})
+		}
+	}
+	return metrics
+}
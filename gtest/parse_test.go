@@ -16,14 +16,16 @@
 package gtest
 
 import (
-	"bufio"
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"text/template"
+
+	"github.com/ysqi/gcodesharp/coverage"
 )
 
 func TestParse(t *testing.T) {
@@ -45,18 +47,28 @@ func TestParse(t *testing.T) {
 		"parallel.txt",
 		"coverage.txt",
 		"multipkg-coverage.txt",
+		"coverpkg.txt",
+		"bench.txt",
 		"syntax-error.txt",
 		"panic.txt",
 		"empty.txt",
 		"race.txt",
+		"subtest.txt",
+		"json_pass.json",
+		"json_mixed.json",
+		"json_panic.json",
+		"json_build-failed.json",
+		"json_dep-build-failed.json",
+		"json_subtest.json",
+		"json_bench.json",
 	}
 	for _, c := range testcases {
 		file, err := os.Open(filepath.Join("./testdata", c))
 		if err != nil {
 			t.Fatal(err)
 		}
-		scanner := bufio.NewScanner(file)
-		pkgs, err := parse(scanner, false)
+		pkgs, err := parseOutput(file, nil)
+		file.Close()
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -78,13 +90,278 @@ func TestParse(t *testing.T) {
 }
 
 var contentTpl = `{{range .}}package {{.Name}} test {{if .Failed}}failed{{else}}passed{{end}}
-Coverage: {{if eq .Coverage -1.0}}unset{{else}}{{printf "%.2f" .Coverage}}%{{end}}
+Coverage: {{if eq .Coverage -1.0}}unset{{else}}{{printf "%.2f" .Coverage}}%{{end}}{{range .Covers}} | {{.Target}}: {{printf "%.2f" .Percent}}%{{end}}
 Cost: {{printf "%.3f" .Cost}} second
 Pass: {{.PassCount}}, Fail: {{.FailCount}}, Skip: {{.SkipCount}}
 Failed cause:{{.Err}}
 Tests:
-{{range .Units}}	+{{.Result}}	{{.Name}}	Spend time={{printf "%.3f" .Cost}} sencond	Output:{{if .Output}}
+{{range .AllUnits}}	+{{.Result}}	{{.Name}}	Spend time={{printf "%.3f" .Cost}} sencond	Output:{{if .Output}}
 {{.Output}}{{else}}<nil>{{end}}
+{{end}}{{range .Benchmarks}}	~{{.}}
 {{end}}
 {{end}}
 `
+
+func TestJSONSupported(t *testing.T) {
+	cases := map[string]bool{
+		"go1.4":           false,
+		"go1.9.2":         false,
+		"go1.10":          true,
+		"go1.21.3":        true,
+		"go2.0":           true,
+		"devel +a1b2c3d4": true,
+		"":                false,
+	}
+	for version, want := range cases {
+		if got := jsonSupported(version); got != want {
+			t.Fatalf("jsonSupported(%q) want %v, got %v", version, want, got)
+		}
+	}
+}
+
+func TestSubtestTree(t *testing.T) {
+	file, err := os.Open("./testdata/json_subtest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	pkgs, err := parseOutput(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := pkgs[0]
+	if len(pkg.Units) != 2 {
+		t.Fatalf("want 2 top-level tests, got %d", len(pkg.Units))
+	}
+	foo := pkg.Units[0]
+	if len(foo.Children) != 2 {
+		t.Fatalf("want TestFoo has 2 subtests, got %d", len(foo.Children))
+	}
+	deep := findUnitTest(pkg.Units, "TestFoo/case_2/deep")
+	if deep == nil || deep.Parent != foo.Children[1] || deep.Root() != foo {
+		t.Fatal("want TestFoo/case_2/deep is the child of TestFoo/case_2")
+	}
+	if deep.SubName() != "case_2/deep" || deep.Depth() != 2 {
+		t.Fatalf("want sub name case_2/deep and depth 2, got %s and %d", deep.SubName(), deep.Depth())
+	}
+	if bar := findUnitTest(pkg.Units, "TestBar/a/b"); bar == nil || bar.Parent != pkg.Units[1] {
+		t.Fatal("want TestBar/a/b is the child of TestBar")
+	}
+
+	// parent failed if any child failed
+	foo.Result, foo.Children[1].Result = PASS, PASS
+	pkg.aggregate()
+	if foo.Result != FAIL || foo.Children[1].Result != FAIL {
+		t.Fatal("want the parents of failed subtest are failed")
+	}
+
+	suites, err := (&Report{Packages: pkgs}).ToJunit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range suites.Suites[0].TestCases {
+		got = append(got, c.Classname+":"+c.Name)
+	}
+	want := "subtest:TestFoo,TestFoo:case_1,TestFoo:case_2,TestFoo:case_2/deep,subtest:TestBar,TestBar:a/b"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("want junit test cases %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+func TestFindings(t *testing.T) {
+	file, err := os.Open("./testdata/json_subtest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	pkgs, err := parseOutput(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs[0].Dir = "/go/src/package/subtest"
+	list := (&Report{Packages: pkgs}).Findings()
+	// only the failed leaf test is reported
+	if len(list) != 1 {
+		t.Fatalf("want 1 finding, got %d", len(list))
+	}
+	f := list[0]
+	if f.Rule != "TestFoo/case_2/deep" || f.File != "/go/src/package/subtest/s_test.go" || f.Line != 12 {
+		t.Fatalf("want finding of TestFoo/case_2/deep at s_test.go:12, got %+v", f)
+	}
+	if !strings.Contains(f.Message, "deep failed") {
+		t.Fatalf("want message contains the test output, got %q", f.Message)
+	}
+}
+
+func TestTotalCoverage(t *testing.T) {
+	parse := func(s string) []*coverage.Profile {
+		profiles, err := coverage.ParseProfiles(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return profiles
+	}
+	// the tests of foo cover foo and bar by -coverpkg, bar covers itself.
+	foo := &Package{Name: "example.com/foo", Coverage: -1, Profiles: parse(`mode: set
+example.com/foo/foo.go:3.14,5.2 2 1
+example.com/foo/foo.go:7.14,9.2 2 0
+example.com/bar/bar.go:3.14,5.2 1 1
+example.com/bar/bar.go:7.14,9.2 3 0
+`)}
+	bar := &Package{Name: "example.com/bar", Coverage: -1, Profiles: parse(`mode: set
+example.com/bar/bar.go:3.14,5.2 1 0
+example.com/bar/bar.go:7.14,9.2 3 1
+`)}
+	for _, pkg := range []*Package{foo, bar} {
+		pkg.setCovers(coverByPackage(pkg.Profiles))
+	}
+	if len(foo.Covers) != 2 || foo.Covers[0].Target != "example.com/bar" || foo.Covers[0].Percent != 25 {
+		t.Fatalf("want foo covers bar 25%% and itself, got %+v", foo.Covers)
+	}
+	if foo.Coverage != 50 || bar.Coverage != 75 {
+		t.Fatalf("want the coverage of foo and bar are 50%% and 75%%, got %.2f and %.2f", foo.Coverage, bar.Coverage)
+	}
+	if covers := foo.OtherCovers(); len(covers) != 1 || covers[0].Target != "example.com/bar" {
+		t.Fatalf("want foo covers other package bar, got %+v", covers)
+	}
+	// 6 of 8 statements are covered after merged, the average 62.5% is wrong.
+	if total := (&Report{Packages: []*Package{foo, bar}}).TotalCoverage(); total != 75 {
+		t.Fatalf("want total coverage 75%%, got %.2f", total)
+	}
+	if total := (&Report{}).TotalCoverage(); total != -1 {
+		t.Fatalf("want total coverage -1 without profile, got %.2f", total)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	b := parseBenchmark("BenchmarkBar/size-10-8 \t  200\t  51.5 ns/op\t 3.5 widgets/op\t 16 B/op\t 1 allocs/op")
+	if b == nil || b.Name != "BenchmarkBar/size-10" || b.Procs != 8 || b.N != 200 {
+		t.Fatalf("want BenchmarkBar/size-10 with 8 procs and 200 iterations, got %+v", b)
+	}
+	for unit, want := range map[string]float64{"ns/op": 51.5, "widgets/op": 3.5, "B/op": 16, "allocs/op": 1} {
+		if got, ok := b.Metric(unit); !ok || got != want {
+			t.Fatalf("want %s is %g, got %g", unit, want, got)
+		}
+	}
+	if _, ok := b.Metric("MB/s"); ok {
+		t.Fatal("want MB/s is not reported")
+	}
+	if parseBenchmark("BenchmarkBar/size") != nil || parseBenchmark("ok  \tpackage/bench\t0.005s") != nil {
+		t.Fatal("want the benchmark name line and package result line are not benchmark result")
+	}
+
+	props := benchProperties([]*Benchmark{b})
+	var got []string
+	for _, p := range props {
+		got = append(got, p.Name+"="+p.Value)
+	}
+	want := "benchmark.BenchmarkBar/size-10.ns/op=51.5,benchmark.BenchmarkBar/size-10.B/op=16," +
+		"benchmark.BenchmarkBar/size-10.allocs/op=1,benchmark.BenchmarkBar/size-10.widgets/op=3.5"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("want junit properties %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+func TestFlaky(t *testing.T) {
+	file, err := os.Open("./testdata/json_subtest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	pkgs, err := parseOutput(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := pkgs[0]
+	foo := pkg.Units[0]
+	// TestFoo failed at first and passed on the second rerun.
+	foo.Retries = []*Unit{{Name: foo.Name, Result: FAIL, Output: "rerun failed"}, {Name: foo.Name, Result: PASS}}
+	foo.markFlaky()
+	if pkg.FailCount() != 0 || pkg.FlakyCount() != 3 {
+		t.Fatalf("want TestFoo and its 2 failed subtests are flaky, got fail %d and flaky %d", pkg.FailCount(), pkg.FlakyCount())
+	}
+	r := &Report{Packages: pkgs}
+	suites, err := r.ToJunit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := suites.Suites[0].TestCases[0]
+	if tc.Name != "TestFoo" || tc.Failure != nil || len(tc.FlakyFailures) != 2 || tc.FlakyFailures[1].StackTrace != "rerun failed" {
+		t.Fatalf("want TestFoo has 2 flaky failures and no failure, got %+v", tc)
+	}
+	if suites.Suites[0].Failures != 0 {
+		t.Fatalf("want no failure in test suite, got %d", suites.Suites[0].Failures)
+	}
+
+	var legacy bytes.Buffer
+	if err := JUnitReportXML(r, true, &legacy); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(legacy.String(), "<failure") || strings.Count(legacy.String(), "<flakyFailure") != 4 {
+		t.Fatalf("want the flaky tests mapped in legacy junit report, got:\n%s", legacy.String())
+	}
+
+	var buf bytes.Buffer
+	if err := r.TOutput(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "flaky tests:\n    package/subtest\tTestFoo\tpassed on rerun 2\n") {
+		t.Fatalf("want the flaky tests in text report, got:\n%s", buf.String())
+	}
+}
+
+func TestJSONReport(t *testing.T) {
+	file, err := os.Open("./testdata/json_subtest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	pkgs, err := parseOutput(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal((&Report{Packages: pkgs}).JReport())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"result":"FAIL"`) {
+		t.Fatalf("want the result encoded as name, got %s", data)
+	}
+	var r JSONReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Packages) != 1 || r.Packages[0].Name != pkgs[0].Name || len(r.Packages[0].Tests) != len(pkgs[0].Units) {
+		t.Fatalf("want the same packages after decoded, got %+v", r.Packages)
+	}
+	foo := r.Packages[0].Tests[0]
+	if foo.Name != "TestFoo" || len(foo.Subtests) != 2 || len(foo.Subtests[1].Subtests) != 1 ||
+		foo.Subtests[1].Subtests[0].Name != "TestFoo/case_2/deep" {
+		t.Fatalf("want the subtests nested in its parent, got %+v", foo)
+	}
+	var res Result
+	if err := json.Unmarshal([]byte(`"FLAKY"`), &res); err != nil || res != FLAKY {
+		t.Fatalf("want FLAKY, got %s %v", res, err)
+	}
+}
+
+func TestCancel(t *testing.T) {
+	output := `{"Action":"run","Package":"p","Test":"TestFast"}
+{"Action":"pass","Package":"p","Test":"TestFast","Elapsed":0}
+{"Action":"run","Package":"p","Test":"TestSlow"}
+{"Action":"output","Package":"p","Test":"TestSlow","Output":"working\n"}
+`
+	pkgs, err := parseOutput(strings.NewReader(output), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := pkgs[0]
+	pkg.cancel("go test is canceled")
+	if !pkg.Failed || pkg.PassCount() != 1 || pkg.FailCount() != 1 {
+		t.Fatalf("want the finished test kept and the running test failed, got pass %d fail %d", pkg.PassCount(), pkg.FailCount())
+	}
+	if slow := pkg.Units[1]; slow.Output != "working\ngo test is canceled" {
+		t.Fatalf("want the cancel reason in output of running test, got %q", slow.Output)
+	}
+}
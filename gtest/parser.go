@@ -25,6 +25,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/ysqi/gcodesharp/coverage"
 )
 
 // Result represents a test result.
@@ -35,6 +37,8 @@ const (
 	PASS Result = iota
 	FAIL
 	SKIP
+	// FLAKY the test failed but passed on rerun, see Config.Rerun.
+	FLAKY
 )
 
 func toResult(name string) Result {
@@ -51,31 +55,155 @@ func toResult(name string) Result {
 
 // Unit single test funcation
 type Unit struct {
-	Name   string
-	Cost   float32
-	Result Result
-	Output string
+	// Name the full name of test,the subtest name look like: TestFoo/case_1
+	Name string
+	Cost float32
+	// Runtime the time when test start running
+	Runtime time.Time
+	Result  Result
+	Output  string
+
+	// Parent the test which run this subtest, nil if it is a top-level test.
+	Parent *Unit `json:"-"`
+	// Children the subtests which run by t.Run in this test.
+	Children []*Unit
+	// Retries the result of each rerun after this test failed, the last one is passed if it is FLAKY.
+	Retries []*Unit
+
+	done bool // the result of test is reported
+}
+
+// Root return the top-level test of this unit.
+func (u *Unit) Root() *Unit {
+	root := u
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	return root
+}
+
+// SubName return the name of subtest without the top-level test name,
+// e.g: TestFoo/case_1/a => case_1/a
+// return the name if it is a top-level test.
+func (u *Unit) SubName() string {
+	if u.Parent == nil {
+		return u.Name
+	}
+	return strings.TrimPrefix(u.Name, u.Root().Name+"/")
+}
+
+// Depth return the level of subtest, zero if it is a top-level test.
+func (u *Unit) Depth() int {
+	depth := 0
+	for p := u.Parent; p != nil; p = p.Parent {
+		depth++
+	}
+	return depth
+}
+
+// markFlaky set the test and its failed subtests are flaky.
+func (u *Unit) markFlaky() {
+	if u.Result == FAIL {
+		u.Result = FLAKY
+	}
+	for _, c := range u.Children {
+		c.markFlaky()
+	}
+}
+
+// aggregate set the test failed if any of subtests failed.
+func (u *Unit) aggregate() {
+	for _, c := range u.Children {
+		c.aggregate()
+		if c.Result == FAIL {
+			u.Result = FAIL
+		}
+	}
 }
 
 // Package is a single package that contains test results
 type Package struct {
-	Name    string
+	Name string
+	// Dir the package dir, it is empty if the result is parsed from output.
+	Dir     string
 	Cost    float32
 	Runtime time.Time
-	// Coverage package test coverage
-	// TODO: need support with '-coverpkg' arg，the result such like :
-	// 		ok      github.com/ysqi/gcodereview/gtest       0.011s  coverage: 12.5% of statements in fmt
-	// Current only support that (full package testting converage):
+	// Coverage the coverage of package itself, it is -1 if unknown, e.g:
 	//		ok      github.com/ysqi/gcodereview/gtest       0.010s  coverage: 47.7% of statements
+	// the coverage of other packages by '-coverpkg' is in Covers, e.g:
+	// 		ok      github.com/ysqi/gcodereview/gtest       0.011s  coverage: 12.5% of statements in fmt
 	Coverage float32
+	// Covers the coverage of each target package which is covered by the tests of this package.
+	Covers []Cover
+	// Profiles the cover profile of each file, it is nil if the test does not run with cover.
+	Profiles []*coverage.Profile
 	Failed   bool
 	Err      string
-	Units    []*Unit
+	// Units the top-level tests, the subtests are in Children of its parent.
+	Units []*Unit
+	// Benchmarks the benchmark results in run order, it is nil if the test does not run benchmarks.
+	Benchmarks []*Benchmark
+}
+
+// Cover the coverage of a target package.
+type Cover struct {
+	// Target the import path of covered package,
+	// it may be the package list of '-coverpkg' if it is parsed from output, e.g: fmt, strings
+	Target  string
+	Percent float32
+}
+
+// AllUnits return all tests of package,include subtests.
+// the subtests follow its parent test.
+func (pkg *Package) AllUnits() []*Unit {
+	var list []*Unit
+	var walk func(units []*Unit)
+	walk = func(units []*Unit) {
+		for _, u := range units {
+			list = append(list, u)
+			walk(u.Children)
+		}
+	}
+	walk(pkg.Units)
+	return list
+}
+
+// addUnit add test to package, the subtest is added as the child of its parent.
+func (pkg *Package) addUnit(u *Unit) {
+	// find the nearest parent, e.g: TestFoo/a/b => TestFoo/a => TestFoo
+	for i := strings.LastIndex(u.Name, "/"); i > 0; i = strings.LastIndex(u.Name[:i], "/") {
+		if parent := findUnitTest(pkg.Units, u.Name[:i]); parent != nil {
+			u.Parent = parent
+			parent.Children = append(parent.Children, u)
+			return
+		}
+	}
+	pkg.Units = append(pkg.Units, u)
+}
+
+// aggregate the result of subtests to its parent.
+func (pkg *Package) aggregate() {
+	for _, u := range pkg.Units {
+		u.aggregate()
+	}
+}
+
+// cancel fail the package and the tests which are not done, e.g: the go test is killed.
+func (pkg *Package) cancel(reason string) {
+	for _, u := range pkg.AllUnits() {
+		if !u.done {
+			u.Result = FAIL
+			u.Output = appendLine(u.Output, reason)
+		}
+	}
+	pkg.aggregate()
+	pkg.Failed = true
+	pkg.Err = appendLine(pkg.Err, reason)
 }
 
 func (pkg *Package) getCount(r Result) int {
 	count := 0
-	for _, unit := range pkg.Units {
+	for _, unit := range pkg.AllUnits() {
 		if unit.Result == r {
 			count++
 		}
@@ -94,6 +222,11 @@ func (pkg *Package) PassCount() int {
 
 }
 
+// FlakyCount counts the number of flaky tests
+func (pkg *Package) FlakyCount() int {
+	return pkg.getCount(FLAKY)
+}
+
 // SkipCount counts the number of skip tests
 func (pkg *Package) SkipCount() int {
 	return pkg.getCount(SKIP)
@@ -105,10 +238,37 @@ func (pkg *Package) HasCoverage() bool {
 	return pkg.Coverage >= 0.00
 }
 
+// setCoverage set the coverage of package itself if target is empty,
+// otherwise set the coverage of the target packages, e.g: coverage: 12.5% of statements in fmt
+func (pkg *Package) setCoverage(percent float32, target string) {
+	if target == "" {
+		pkg.Coverage = percent
+		return
+	}
+	for i, c := range pkg.Covers {
+		if c.Target == target {
+			pkg.Covers[i].Percent = percent
+			return
+		}
+	}
+	pkg.Covers = append(pkg.Covers, Cover{Target: target, Percent: percent})
+}
+
+// OtherCovers return the coverage of target packages except the package itself.
+func (pkg *Package) OtherCovers() []Cover {
+	var list []Cover
+	for _, c := range pkg.Covers {
+		if c.Target != pkg.Name {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
 // GetByResult seach the same result of all unit test
 func (pkg *Package) GetByResult(r Result) []*Unit {
 	s := []*Unit{}
-	for _, unit := range pkg.Units {
+	for _, unit := range pkg.AllUnits() {
 		if unit.Result == r {
 			s = append(s, unit)
 		}
@@ -119,8 +279,10 @@ func (pkg *Package) GetByResult(r Result) []*Unit {
 var (
 	// panic error
 	regPanic = regexp.MustCompile(`^panic: (.* \[recovered\])|(test timed out after)`)
-	// coverage info ,the string look like :coverage: 36.4% of statements
-	regCoverage = regexp.MustCompile(`^coverage: (\d+\.{0,1}\d+)% of statements(?:\sin\s.+)?`)
+	// coverage info ,the string look like :
+	//	coverage: 36.4% of statements
+	//	coverage: 12.5% of statements in fmt, strings
+	regCoverage = regexp.MustCompile(`^coverage: (\d+\.{0,1}\d+)% of statements(?:\sin\s(.+))?`)
 
 	// test method pass,like:
 	//	--- PASS: TestAddressHexChecksum (0.00s)
@@ -129,17 +291,22 @@ var (
 	regStatus = regexp.MustCompile(`\t*--- (PASS|FAIL|SKIP): (.+) \((\d+\.\d+)(?: seconds|s)\)`)
 	// one test running,  === RUN   TestParse
 	regUnitTestStart = regexp.MustCompile(`^\t*=== RUN\s+(\S+)$`)
+	// the output of other test follows this line, like:
+	//	=== PAUSE TestParse
+	//	=== CONT  TestParse
+	//	=== NAME  TestParse
+	regUnitTestSwitch = regexp.MustCompile(`^\t*=== (PAUSE|CONT|NAME)\s+(\S+)$`)
 	// package test result,like:
 	//	ok          github.com/ysqi/com     1.211s
 	//	ok          github.com/ysqi/com     0.00s	[no tests to run]
 	//	FAIL        github.com/ysqi/com     0.005s
 	//  FAIL	github.com/ysqi/com [setup failed]
 	//  ?	github.com/ysqi/com 	[no test files]
-	regexResult = regexp.MustCompile(`(ok|FAIL|\?)\s+([^ ]+)\s+(?:(\d+\.\d+)s|\[([\w\s]+)\])(?:\s+coverage:\s+(\d+\.{0,1}\d+)%\sof\sstatements(?:\sin\s.+)?)?`)
+	regexResult = regexp.MustCompile(`(ok|FAIL|\?)\s+([^ ]+)\s+(?:(\d+\.\d+)s|\[([\w\s]+)\])(?:\s+coverage:\s+(\d+\.{0,1}\d+)%\sof\sstatements(?:\sin\s(.+))?)?`)
 	// regexResult = regexp.MustCompile(`^(ok|FAIL|\?)\s+([^ ]+)\s+(?:(\d+\.\d+)\s|(\[\w+ failed\]))(?:\s+coverage:\s+(\d+\.\d+)%\sof\sstatements(?:\sin\s.+)?)?$`)
 )
 
-func parse(scanner *bufio.Scanner, logprint bool) ([]*Package, error) {
+func parse(scanner *bufio.Scanner, output func(line string)) ([]*Package, error) {
 	var (
 		// pakcage array
 		pkgs = []*Package{}
@@ -163,8 +330,8 @@ func parse(scanner *bufio.Scanner, logprint bool) ([]*Package, error) {
 	scanner.Split(bufio.ScanLines)
 	for scanner.Scan() {
 		line := scanner.Text()
-		if logprint {
-			log.Println(line)
+		if output != nil {
+			output(line)
 		}
 		if pkg == nil {
 			pkg = newPkg("")
@@ -178,9 +345,22 @@ func parse(scanner *bufio.Scanner, logprint bool) ([]*Package, error) {
 				pkg = newPkg("")
 			}
 			curUnit = &Unit{
-				Name: string(matches[1]),
+				Name:    string(matches[1]),
+				Runtime: time.Now(),
+			}
+			pkg.addUnit(curUnit)
+			continue
+		}
+		if matches := regUnitTestSwitch.FindSubmatch(data); len(matches) == 3 {
+			if string(matches[1]) != "PAUSE" {
+				if u := findUnitTest(pkg.Units, string(matches[2])); u != nil {
+					curUnit = u
+				}
 			}
-			pkg.Units = append(pkg.Units, curUnit)
+			continue
+		}
+		if pkg.addBenchLine(line) {
+			// e.g: BenchmarkFoo-8   	 1000000	      1234 ns/op
 			continue
 		}
 		if matches := regStatus.FindSubmatch(data); len(matches) == 4 {
@@ -189,9 +369,10 @@ func parse(scanner *bufio.Scanner, logprint bool) ([]*Package, error) {
 			curUnit = findUnitTest(pkg.Units, string(matches[2]))
 			curUnit.Cost = mustFloat32(matches[3])
 			curUnit.Result = toResult(string(matches[1]))
+			curUnit.done = true
 			continue
 		}
-		if matches := regexResult.FindSubmatch(data); len(matches) == 6 {
+		if matches := regexResult.FindSubmatch(data); len(matches) == 7 {
 			pkg.Name = string(matches[2])
 			if p := findPkg(pkgs, pkg.Name); p == nil {
 				pkgs = append(pkgs, pkg)
@@ -211,7 +392,7 @@ func parse(scanner *bufio.Scanner, logprint bool) ([]*Package, error) {
 			}
 			// e.g: ok      github.com/ysqi/gcodereview/gtest       0.024s  coverage: 60.6% of statements
 			if string(matches[5]) != "" {
-				pkg.Coverage = mustFloat32(matches[5])
+				pkg.setCoverage(mustFloat32(matches[5]), string(matches[6]))
 			}
 			// reset
 			pkg = nil
@@ -229,7 +410,7 @@ func parse(scanner *bufio.Scanner, logprint bool) ([]*Package, error) {
 		}
 		if matches := regCoverage.FindSubmatch(data); matches != nil {
 			// e.g:	coverage: 36.4% of statements
-			pkg.Coverage = mustFloat32(matches[1])
+			pkg.setCoverage(mustFloat32(matches[1]), string(matches[2]))
 			continue
 		}
 		if strings.HasPrefix(line, "# ") {
@@ -250,6 +431,9 @@ func parse(scanner *bufio.Scanner, logprint bool) ([]*Package, error) {
 			continue
 		}
 	}
+	for _, p := range pkgs {
+		p.aggregate()
+	}
 	return pkgs, nil
 }
 
@@ -275,11 +459,19 @@ func findPkg(pkgs []*Package, name string) *Package {
 	}
 	return nil
 }
+
+// findUnitTest find test from the list and its subtests by full name.
 func findUnitTest(tests []*Unit, name string) *Unit {
 	for _, u := range tests {
 		if u.Name == name {
 			return u
 		}
+		// only the subtest name is prefixed with the parent name.
+		if strings.HasPrefix(name, u.Name+"/") {
+			if c := findUnitTest(u.Children, name); c != nil {
+				return c
+			}
+		}
 	}
 	return nil
 }
@@ -0,0 +1,304 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TestEvent is a event of `go test -json` output.
+// see `go doc test2json` for more info.
+type TestEvent struct {
+	Time    time.Time // encodes as an RFC3339-format string
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64 // seconds
+	Output  string
+
+	// ImportPath is set for build-output and build-fail action,
+	// look like: github.com/ysqi/com [github.com/ysqi/com.test]
+	ImportPath string
+	// FailedBuild is set for the fail action of package which is not tested because of build failed,
+	// it is the ImportPath of the package which failed to build, e.g: a dependency.
+	FailedBuild string
+}
+
+var (
+	// the output line of test frame, test progress is known from the action,so ignore it:
+	//	=== RUN   TestParse
+	//	=== PAUSE TestParse
+	//	=== CONT  TestParse
+	//	=== NAME  TestParse
+	regFrame = regexp.MustCompile(`^\s*=== (RUN|PAUSE|CONT|NAME)\s+\S+`)
+
+	// go1.10 is the first version which support go test -json.
+	regGoVersion = regexp.MustCompile(`go(\d+)\.(\d+)`)
+)
+
+// jsonSupported check the go version whether support `go test -json`.
+// the version likes go1.9.2, go1.10, devel is treated as supported.
+func jsonSupported(goVersion string) bool {
+	if strings.HasPrefix(goVersion, "devel") {
+		return true
+	}
+	matches := regGoVersion.FindStringSubmatch(goVersion)
+	if len(matches) != 3 {
+		return false
+	}
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	return major > 1 || (major == 1 && minor >= 10)
+}
+
+// parseOutput parse go test output which can be the verbose text or the json events.
+// the parser is selected by the first no-empty char,json parser if it is '{'.
+// the output is called with each line of test output if it is not nil.
+func parseOutput(r io.Reader, output func(line string)) ([]*Package, error) {
+	br := bufio.NewReader(r)
+	isJSON := false
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			break
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			br.ReadByte()
+			continue
+		}
+		isJSON = b[0] == '{'
+		break
+	}
+	scanner := bufio.NewScanner(br)
+	// the json line may be longer than the default max token size.
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	if isJSON {
+		return parseJSON(scanner, output)
+	}
+	return parse(scanner, output)
+}
+
+// parseJSON parse the `go test -json` event stream to package test result.
+// the line which is not a json event is treated as the build error of package.
+// the build error is attached to the tested package which is failed by it,
+// the failed package can be a dependency, so it is not reported as a tested package.
+func parseJSON(scanner *bufio.Scanner, output func(line string)) ([]*Package, error) {
+	var (
+		pkgs = []*Package{}
+		// the package name of last '# package' line
+		buildPkg string
+		// the build output of packages, key is the import path without test suffix
+		builds = map[string]string{}
+		// the build output which is attached to the tested package
+		attached = map[string]bool{}
+	)
+	getPkg := func(name string, t time.Time) *Package {
+		if p := findPkg(pkgs, name); p != nil {
+			return p
+		}
+		if t.IsZero() {
+			t = time.Now()
+		}
+		p := &Package{
+			Name:     name,
+			Runtime:  t,
+			Coverage: -1,
+		}
+		pkgs = append(pkgs, p)
+		return p
+	}
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if data[0] != '{' {
+			// plain text output, e.g: build error.
+			line := string(data)
+			if output != nil {
+				output(line)
+			}
+			if strings.HasPrefix(line, "# ") {
+				buildPkg = trimImportPath(strings.TrimPrefix(line, "# "))
+				continue
+			}
+			if buildPkg != "" {
+				builds[buildPkg] = appendLine(builds[buildPkg], line)
+			}
+			continue
+		}
+
+		var e TestEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return pkgs, err
+		}
+		if output != nil && e.Output != "" {
+			output(strings.TrimSuffix(e.Output, "\n"))
+		}
+
+		switch e.Action {
+		case "build-output":
+			line := strings.TrimRight(e.Output, "\n")
+			if strings.HasPrefix(line, "# ") {
+				continue
+			}
+			name := trimImportPath(e.ImportPath)
+			builds[name] = appendLine(builds[name], line)
+			continue
+		case "build-fail":
+			continue
+		}
+		if e.Package == "" {
+			continue
+		}
+		pkg := getPkg(e.Package, e.Time)
+		if e.Test == "" {
+			if e.FailedBuild != "" {
+				name := trimImportPath(e.FailedBuild)
+				pkg.Err = prependLine(builds[name], pkg.Err)
+				pkg.Failed = true
+				attached[name] = true
+			}
+			handlePackageEvent(pkg, &e)
+			continue
+		}
+
+		unit := findUnitTest(pkg.Units, e.Test)
+		if unit == nil {
+			unit = &Unit{
+				Name:    e.Test,
+				Runtime: e.Time,
+			}
+			pkg.addUnit(unit)
+		}
+		switch e.Action {
+		case "output":
+			line := strings.TrimRight(e.Output, "\n")
+			if regFrame.MatchString(line) || regStatus.MatchString(line) {
+				continue
+			}
+			// the benchmark output may be belong to the last test.
+			if pkg.addBenchLine(line) {
+				continue
+			}
+			unit.Output = appendLine(unit.Output, line)
+		case "pass", "fail", "skip":
+			unit.Result = toResult(strings.ToUpper(e.Action))
+			unit.Cost = float32(e.Elapsed)
+			unit.done = true
+		}
+	}
+	// the go version before go1.24 has not FailedBuild, attach the build output to the package self,
+	// or the package which is failed without test.
+	var names []string
+	for name := range builds {
+		if !attached[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if p := findPkg(pkgs, name); p != nil {
+			p.Err = prependLine(builds[name], p.Err)
+			p.Failed = true
+			continue
+		}
+		for _, p := range pkgs {
+			if p.Failed && len(p.Units) == 0 {
+				p.Err = prependLine(builds[name], p.Err)
+			}
+		}
+	}
+	for _, p := range pkgs {
+		p.aggregate()
+	}
+	return pkgs, scanner.Err()
+}
+
+// handlePackageEvent handle the event of package, which is not belong to a test.
+func handlePackageEvent(pkg *Package, e *TestEvent) {
+	switch e.Action {
+	case "start":
+		pkg.Runtime = e.Time
+	case "pass", "skip":
+		// skip: the package has not test files
+		pkg.Failed = false
+		pkg.Cost = float32(e.Elapsed)
+		// the output is not a error info if package test passed
+		pkg.Err = ""
+	case "fail":
+		pkg.Failed = true
+		pkg.Cost = float32(e.Elapsed)
+	case "output":
+		line := strings.TrimRight(e.Output, "\n")
+		if pkg.addBenchLine(line) {
+			return
+		}
+		data := []byte(line)
+		if matches := regexResult.FindSubmatch(data); len(matches) == 7 {
+			// e.g: FAIL	github.com/ysqi/com [build failed]
+			if string(matches[1]) == "FAIL" && len(matches[4]) > 0 {
+				pkg.Err = appendLine(pkg.Err, string(matches[4]))
+			}
+			if len(matches[5]) > 0 {
+				pkg.setCoverage(mustFloat32(matches[5]), string(matches[6]))
+			}
+			return
+		}
+		if matches := regCoverage.FindSubmatch(data); matches != nil {
+			pkg.setCoverage(mustFloat32(matches[1]), string(matches[2]))
+			return
+		}
+		if line == "PASS" || line == "FAIL" || strings.HasPrefix(line, "exit status ") ||
+			line == "testing: warning: no tests to run" {
+			return
+		}
+		if strings.TrimSpace(line) != "" && len(pkg.Units) == 0 {
+			// e.g: panic in TestMain or init func.
+			pkg.Err = appendLine(pkg.Err, line)
+		}
+	}
+}
+
+// prependLine add the lines before str.
+func prependLine(lines, str string) string {
+	if lines == "" {
+		return str
+	}
+	if str == "" {
+		return lines
+	}
+	return appendLine(lines, str)
+}
+
+// trimImportPath remove the test package suffix in import path.
+//
+//	github.com/ysqi/com [github.com/ysqi/com.test] => github.com/ysqi/com
+func trimImportPath(path string) string {
+	if i := strings.Index(path, " ["); i > 0 {
+		return path[:i]
+	}
+	return path
+}
@@ -4,9 +4,9 @@ package gtest
 
 import "fmt"
 
-const _Result_name = "PASSFAILSKIP"
+const _Result_name = "PASSFAILSKIPFLAKY"
 
-var _Result_index = [...]uint8{0, 4, 8, 12}
+var _Result_index = [...]uint8{0, 4, 8, 12, 17}
 
 func (i Result) String() string {
 	if i < 0 || i >= Result(len(_Result_index)-1) {
@@ -0,0 +1,82 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gtest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TOutput write the plain text report of go test, it lists the result of each package,
+// the failed tests with output, and the flaky tests separately.
+func (r *Report) TOutput(w io.Writer) error {
+	var pass, fail, skip, flaky int
+	var flakyUnits []string
+	for _, pkg := range r.Packages {
+		pass += pkg.PassCount()
+		fail += pkg.FailCount()
+		skip += pkg.SkipCount()
+		flaky += pkg.FlakyCount()
+	}
+	if _, err := fmt.Fprintf(w, "go test: %d packages, pass %d, fail %d, flaky %d, skip %d, %.3fs\n",
+		len(r.Packages), pass, fail, flaky, skip, r.Cost); err != nil {
+		return err
+	}
+	for _, pkg := range r.Packages {
+		status := "ok"
+		if pkg.Failed {
+			status = "FAIL"
+		}
+		line := fmt.Sprintf("%s\t%s\t%.3fs", status, pkg.Name, pkg.Cost)
+		if pkg.HasCoverage() {
+			line += fmt.Sprintf("\tcoverage: %.1f%% of statements", pkg.Coverage)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if pkg.Err != "" {
+			if _, err := fmt.Fprintln(w, indent(pkg.Err, "    ")); err != nil {
+				return err
+			}
+		}
+		for _, u := range pkg.AllUnits() {
+			switch u.Result {
+			case FAIL:
+				if _, err := fmt.Fprintf(w, "    --- FAIL: %s (%.2fs)\n", u.Name, u.Cost); err != nil {
+					return err
+				}
+				if u.Output != "" {
+					if _, err := fmt.Fprintln(w, indent(u.Output, "        ")); err != nil {
+						return err
+					}
+				}
+			case FLAKY:
+				flakyUnits = append(flakyUnits, fmt.Sprintf("%s\t%s\tpassed on rerun %d", pkg.Name, u.Name, len(u.Root().Retries)))
+			}
+		}
+	}
+	if len(flakyUnits) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "\nflaky tests:\n    %s\n", strings.Join(flakyUnits, "\n    "))
+	return err
+}
+
+// indent add the prefix to each line of text.
+func indent(text, prefix string) string {
+	return prefix + strings.Replace(strings.TrimRight(text, "\n"), "\n", "\n"+prefix, -1)
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gvet
+
+import (
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// ToolName the tool name of finding.
+const ToolName = "govet"
+
+// Tool return the tool name of findings.
+func (r *Report) Tool() string {
+	return ToolName
+}
+
+// Findings return a finding for each go vet diagnostic,
+// the compile error and load error are error severity, others are warning.
+func (r *Report) Findings() []reporter.Finding {
+	var list []reporter.Finding
+	for _, f := range r.Files {
+		for _, d := range f.Diagnostics {
+			list = append(list, diagnosticFinding(f, d))
+		}
+	}
+	return list
+}
+
+// Suppress remove the diagnostic which is dropped.
+func (r *Report) Suppress(drop func(reporter.Finding) bool) {
+	for _, f := range r.Files {
+		var kept []Diagnostic
+		for _, d := range f.Diagnostics {
+			if !drop(diagnosticFinding(f, d)) {
+				kept = append(kept, d)
+			}
+		}
+		f.Diagnostics = kept
+	}
+}
+
+func diagnosticFinding(f *File, d Diagnostic) reporter.Finding {
+	severity := reporter.SeverityWarning
+	if d.Analyzer == "vet" || d.Analyzer == "load" {
+		severity = reporter.SeverityError
+	}
+	return reporter.Finding{
+		Tool:     ToolName,
+		Rule:     d.Analyzer,
+		Severity: severity,
+		File:     f.Name,
+		Line:     d.Line,
+		Col:      d.Col,
+		EndLine:  d.EndLine,
+		EndCol:   d.EndCol,
+		Message:  d.Message,
+	}
+}
@@ -0,0 +1,408 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gvet
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+type errHander func(fm string, args ...interface{})
+
+// Report go vet result
+type Report struct {
+	Files    []*File
+	ExecPath string
+	Created  time.Time
+	Cost     float32
+	Env      struct {
+		GoVersion string
+		OS        string
+		Arch      string
+	}
+	SysErr error `json:"-"` // the error of go vet, see JError
+}
+
+// Diagnostic is a single problem reported by a vet analyzer.
+type Diagnostic struct {
+	Analyzer string
+	Line     int
+	Col      int
+	EndLine  int
+	EndCol   int
+	Message  string
+}
+
+// File a go file checked by go vet
+type File struct {
+	// Name file name
+	Name        string
+	Diagnostics []Diagnostic
+}
+
+// sortFiles sort the files by name, so the report is same between runs.
+func sortFiles(files []*File) {
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+}
+
+func (f *File) HasProblem() bool {
+	return len(f.Diagnostics) > 0
+}
+
+// Analyzers return the sorted name list of analyzers which report problem in this file.
+func (f *File) Analyzers() []string {
+	var list []string
+	seen := map[string]bool{}
+	for _, d := range f.Diagnostics {
+		if !seen[d.Analyzer] {
+			seen[d.Analyzer] = true
+			list = append(list, d.Analyzer)
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// ByAnalyzer return the problems reported by the analyzer.
+func (f *File) ByAnalyzer(analyzer string) []Diagnostic {
+	var list []Diagnostic
+	for _, d := range f.Diagnostics {
+		if d.Analyzer == analyzer {
+			list = append(list, d)
+		}
+	}
+	return list
+}
+
+func (f *File) ProblemContent() string {
+	if !f.HasProblem() {
+		return ""
+	}
+	str := bytes.NewBufferString("")
+	for _, a := range f.Analyzers() {
+		for _, d := range f.ByAnalyzer(a) {
+			str.WriteString(fmt.Sprintf("line:%d:%d [%s] ", d.Line, d.Col, d.Analyzer))
+			str.WriteString(d.Message)
+			str.WriteString("\n")
+		}
+	}
+	return str.String()
+}
+
+// Config go vet config, it is the "gvet" section of config file.
+type Config struct {
+	Tags []string `yaml:"tags"` // build tags, e.g: [integration]
+	Args []string `yaml:"args"` // more args of go vet, e.g: [-printf=false]
+}
+
+// args return the args of go vet.
+func (c Config) args() []string {
+	var args []string
+	if len(c.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(c.Tags, ","))
+	}
+	return append(args, c.Args...)
+}
+
+type Service struct {
+	Report
+	Config Config
+	// Scheduler runs the go vet of each package, all of them run at once if nil.
+	Scheduler *reporter.Scheduler
+	// Events streams the progress of each package, the events are dropped if nil.
+	Events *reporter.EventBus
+
+	ctx  *context.Context
+	jobs *reporter.Jobs
+
+	errh errHander
+}
+
+func New(ctx *context.Context, errh errHander) (*Service, error) {
+	return &Service{
+		ctx:  ctx,
+		errh: errh,
+	}, nil
+}
+
+// Run go vet and block until done, the go vet processes are killed if ctx is done.
+func (s *Service) Run(ctx gocontext.Context) error {
+	s.Created = time.Now()
+	s.Env.GoVersion = runtime.Version()
+	s.Env.OS = runtime.GOOS
+	s.Env.Arch = runtime.GOARCH
+	s.ExecPath = "go vet"
+
+	s.jobs = reporter.NewJobs(ctx, "gvet", s.Scheduler, s.Events, s.errh)
+	ctx = s.jobs.Context()
+	results := make([][]*File, len(s.ctx.Packages))
+	for i, p := range s.ctx.Packages {
+		// only the changed packages are checked in diff-aware mode.
+		if !s.ctx.PackageChanged(p) {
+			continue
+		}
+		// batch go vet
+		i, p := i, p
+		s.jobs.Go(p.ImportPath, func() (bool, []reporter.Finding) {
+			results[i] = s.govet(ctx, p)
+			findings := (&Report{Files: results[i]}).Findings()
+			return len(findings) > 0, findings
+		})
+	}
+	// wait for all go vet done
+	s.SysErr = s.jobs.Wait()
+	for _, result := range results {
+		s.Report.Files = append(s.Report.Files, result...)
+	}
+	sortFiles(s.Report.Files)
+	s.Cost = float32(time.Since(s.Created).Seconds())
+	return s.SysErr
+}
+
+func (s *Service) govet(ctx gocontext.Context, p *context.Package) []*File {
+	if p.HasLoadError() {
+		// go vet can not check the package which can not be loaded.
+		return loadErrors(p)
+	}
+	var files []string
+	for _, list := range [][]string{p.GoFiles, p.CgoFiles, p.TestGoFiles, p.XTestGoFiles} {
+		for _, f := range list {
+			if !filepath.IsAbs(f) {
+				f = filepath.Join(p.Dir, f)
+			}
+			files = append(files, f)
+		}
+	}
+	result, err := runGoVet(ctx, p.Dir, p.ImportPath, s.Config.args(), files...)
+	// the error of killed go vet is not a failure.
+	if err != nil && ctx.Err() == nil {
+		s.jobs.Error(err.Error())
+	}
+	// only the problems on changed lines are reported in diff-aware mode.
+	for _, f := range result {
+		var kept []Diagnostic
+		for _, d := range f.Diagnostics {
+			if s.ctx.ChangedLine(f.Name, d.Line) {
+				kept = append(kept, d)
+			}
+		}
+		f.Diagnostics = kept
+	}
+	return result
+}
+
+var (
+	// Match problem print info, e.g:
+	//
+	//	vet: ./bad.go:8:2: undefined: x
+	//	./bad.go:8:2: self-assignment of x
+	regLine = regexp.MustCompile(`^(?:vet: )?(.+\.go):(\d+):(\d+):\s*(.*)$`)
+	// Match position of json output, e.g: /go/src/a/bad.go:8:2
+	regPosn = regexp.MustCompile(`^(.+\.go):(\d+):(\d+)$`)
+)
+
+// vetPackageJSON is the go vet -json output of one package.
+//
+//	{"pkgpath": {"analyzer": [{"posn": "file:line:col", "end": "file:line:col", "message": "..."}]}}
+type vetPackageJSON map[string]map[string][]struct {
+	Posn    string `json:"posn"`
+	End     string `json:"end"`
+	Message string `json:"message"`
+}
+
+func runGoVet(ctx gocontext.Context, dir, importPath string, args []string, files ...string) ([]*File, error) {
+	var (
+		stderr bytes.Buffer
+		stdout bytes.Buffer
+	)
+	cmd := exec.Command("go", "vet", "-json")
+	cmd.Args = append(cmd.Args, args...)
+	cmd.Args = append(cmd.Args, importPath)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	cmd.Stdout = &stdout
+	waitErr := context.RunCommand(ctx, cmd)
+
+	var result []*File
+	for _, f := range files {
+		result = append(result, &File{
+			Name: f,
+		})
+	}
+	// vet print json to stdout or stderr that depend on go version.
+	count := 0
+	for _, output := range []string{stdout.String(), stderr.String()} {
+		n, err := parse(output, dir, &result)
+		if err != nil {
+			return result, err
+		}
+		count += n
+	}
+	if waitErr != nil && count == 0 {
+		s := stderr.String()
+		if s != "" {
+			return result, errors.New(s + "\n" + waitErr.Error())
+		}
+		return result, waitErr
+	}
+	return result, nil
+}
+
+// parse the go vet output and add diagnostics to file of result.
+// the output can be json or plain text, the relative file name will join with dir.
+// return the number of diagnostics found.
+func parse(output, dir string, result *[]*File) (int, error) {
+	var (
+		count    int
+		jsonData bytes.Buffer
+	)
+	add := func(name string, d Diagnostic) {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		count++
+		for _, f := range *result {
+			if f.Name == name {
+				f.Diagnostics = append(f.Diagnostics, d)
+				return
+			}
+		}
+		*result = append(*result, &File{Name: name, Diagnostics: []Diagnostic{d}})
+	}
+	inJSON := false
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case inJSON || strings.HasPrefix(line, "{"):
+			inJSON = line != "}"
+			jsonData.WriteString(line)
+			jsonData.WriteString("\n")
+		case strings.HasPrefix(line, "#"):
+			// package name, e.g: # github.com/ysqi/gcodesharp
+		default:
+			if matches := regLine.FindStringSubmatch(line); len(matches) == 5 {
+				// compile error or diagnostic of old vet
+				add(matches[1], Diagnostic{
+					Analyzer: "vet",
+					Line:     mustInt(matches[2]),
+					Col:      mustInt(matches[3]),
+					Message:  matches[4],
+				})
+			}
+		}
+	}
+
+	dec := json.NewDecoder(&jsonData)
+	for dec.More() {
+		var pkgs vetPackageJSON
+		if err := dec.Decode(&pkgs); err != nil {
+			return count, fmt.Errorf("decode go vet json output:%s", err)
+		}
+		for _, analyzers := range pkgs {
+			for analyzer, list := range analyzers {
+				for _, item := range list {
+					matches := regPosn.FindStringSubmatch(item.Posn)
+					if len(matches) != 4 {
+						continue
+					}
+					d := Diagnostic{
+						Analyzer: analyzer,
+						Line:     mustInt(matches[2]),
+						Col:      mustInt(matches[3]),
+						Message:  item.Message,
+					}
+					if end := regPosn.FindStringSubmatch(item.End); len(end) == 4 {
+						d.EndLine, d.EndCol = mustInt(end[2]), mustInt(end[3])
+					}
+					add(matches[1], d)
+				}
+			}
+		}
+	}
+	for _, f := range *result {
+		sort.SliceStable(f.Diagnostics, func(i, j int) bool {
+			a, b := f.Diagnostics[i], f.Diagnostics[j]
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Col < b.Col
+		})
+	}
+	return count, nil
+}
+
+// loadErrors convert the load errors of package to the diagnostic of file,
+// the error without position is reported to the package dir.
+func loadErrors(p *context.Package) []*File {
+	var result []*File
+	add := func(name string, d Diagnostic) {
+		for _, f := range result {
+			if f.Name == name {
+				f.Diagnostics = append(f.Diagnostics, d)
+				return
+			}
+		}
+		result = append(result, &File{Name: name, Diagnostics: []Diagnostic{d}})
+	}
+	errs := p.DepsErrors
+	if p.Error != nil {
+		errs = append([]*context.PackageError{p.Error}, errs...)
+	}
+	for _, e := range errs {
+		d := Diagnostic{
+			Analyzer: "load",
+			Message:  e.Err,
+		}
+		name := p.Dir
+		if name == "" {
+			name = p.ImportPath
+		}
+		if matches := regPosn.FindStringSubmatch(e.Pos); len(matches) == 4 {
+			name = matches[1]
+			d.Line, d.Col = mustInt(matches[2]), mustInt(matches[3])
+		}
+		add(name, d)
+	}
+	return result
+}
+
+// mustInt convert string to int number.
+// panic if parse failed.
+func mustInt(s string) int {
+	if len(s) == 0 {
+		return 0
+	}
+	val, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		panic("mustInt:" + err.Error())
+	}
+	return int(val)
+}
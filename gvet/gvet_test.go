@@ -0,0 +1,133 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gvet
+
+import (
+	gocontext "context"
+	"go/build"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ysqi/gcodesharp/context"
+)
+
+func TestRun(t *testing.T) {
+	ctx, err := context.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkgs, err := context.ListPackages("", "./testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx.Packages = append(ctx.Packages, pkgs...)
+	s, err := New(ctx, func(fmt_ string, args ...interface{}) {
+		t.Fatalf(fmt_, args...)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Run(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if f := find(t, s.Report.Files, "bad.go"); !f.HasProblem() {
+		t.Fatal("want report bad.go problem")
+	} else if con := f.ProblemContent(); !strings.Contains(con, "[printf]") {
+		t.Fatalf("want report a printf problem in bad.go, got %s", con)
+	}
+	if f := find(t, s.Report.Files, "good.go"); f.HasProblem() {
+		t.Fatalf("want no problem in good.go, got %s", f.ProblemContent())
+	}
+}
+
+func TestParse(t *testing.T) {
+	data, err := ioutil.ReadFile("./testdata/vet.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(data) + "vet: ./testdata/bad2.go:3:1: expected declaration, found x\n"
+	dir := "/go/src/github.com/ysqi/gcodesharp/gvet"
+	var files []*File
+	count, err := parse(output, dir, &files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Fatalf("want found 4 problems, got %d", count)
+	}
+	if len(files) != 2 {
+		t.Fatalf("want 2 files, got %d", len(files))
+	}
+	bad := find(t, files, "bad.go")
+	if got := strings.Join(bad.Analyzers(), ","); got != "assign,copylocks,printf" {
+		t.Fatalf("want analyzers assign,copylocks,printf, got %s", got)
+	}
+	d := bad.ByAnalyzer("copylocks")
+	if len(d) != 1 || d[0].Line != 24 || d[0].Col != 12 || d[0].EndCol != 22 {
+		t.Fatalf("want copylocks problem at 24:12-24:22, got %+v", d)
+	}
+	if bad.Diagnostics[0].Analyzer != "copylocks" {
+		t.Fatalf("want problems sorted by line, got %+v", bad.Diagnostics)
+	}
+	bad2 := find(t, files, "bad2.go")
+	if bad2.Name != filepath.Join(dir, "testdata", "bad2.go") {
+		t.Fatalf("want absolute file name, got %s", bad2.Name)
+	}
+}
+
+func find(t *testing.T, l []*File, name string) *File {
+	for _, f := range l {
+		if filepath.Base(f.Name) == name {
+			return f
+		}
+	}
+	t.Fatalf("not find file %s", name)
+	return nil
+}
+
+func TestLoadErrors(t *testing.T) {
+	p := &context.Package{
+		Package: &build.Package{
+			Dir:        "/go/src/a",
+			ImportPath: "a",
+		},
+		Error: &context.PackageError{Pos: "/go/src/a/a.go:3:8", Err: "could not import b"},
+		DepsErrors: []*context.PackageError{
+			{Err: "cannot find package c"},
+		},
+	}
+	files := loadErrors(p)
+	if len(files) != 2 {
+		t.Fatalf("want 2 files, got %d", len(files))
+	}
+	if f := find(t, files, "a.go"); len(f.Diagnostics) != 1 || f.Diagnostics[0].Line != 3 || f.Diagnostics[0].Analyzer != "load" {
+		t.Fatalf("want load error at a.go:3, got %+v", f.Diagnostics)
+	}
+	if files[1].Name != "/go/src/a" {
+		t.Fatalf("want report error without position to package dir, got %s", files[1].Name)
+	}
+}
+
+func TestConfigArgs(t *testing.T) {
+	c := Config{Tags: []string{"integration", "linux"}, Args: []string{"-printf=false"}}
+	if got := strings.Join(c.args(), " "); got != "-tags integration,linux -printf=false" {
+		t.Fatalf("want the tags joined by comma, got %q", got)
+	}
+}
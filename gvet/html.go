@@ -0,0 +1,73 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gvet
+
+import (
+	"fmt"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+// HTitle return the title of go vet html report.
+func (r *Report) HTitle() string {
+	return "go vet"
+}
+
+// HSummary return the checked file count and problem count.
+func (r *Report) HSummary() string {
+	files, problems := 0, 0
+	for _, f := range r.Files {
+		if f.HasProblem() {
+			files++
+			problems += len(f.Diagnostics)
+		}
+	}
+	level := formater.HTMLLevelPass
+	if problems > 0 {
+		level = formater.HTMLLevelWarn
+	}
+	s := formater.HTMLStat("files checked", len(r.Files), formater.HTMLLevelInfo) +
+		formater.HTMLStat("files with problem", files, level) +
+		formater.HTMLStat("problems", problems, level) +
+		formater.HTMLStat("seconds", fmt.Sprintf("%.2f", r.Cost), formater.HTMLLevelInfo)
+	if r.SysErr != nil {
+		s += formater.HTMLStat("error", r.SysErr.Error(), formater.HTMLLevelFail)
+	}
+	return s
+}
+
+// HGroupDetail return the problems grouped by go file and analyzer.
+func (r *Report) HGroupDetail() []string {
+	var groups []string
+	for _, f := range r.Files {
+		if !f.HasProblem() {
+			continue
+		}
+		rows := make([]formater.HTMLRow, 0, len(f.Diagnostics))
+		for _, a := range f.Analyzers() {
+			for _, d := range f.ByAnalyzer(a) {
+				rows = append(rows, formater.HTMLRow{
+					Level: formater.HTMLLevelWarn,
+					Cells: []interface{}{a, fmt.Sprintf("%d:%d", d.Line, d.Col), d.Message},
+				})
+			}
+		}
+		title := fmt.Sprintf("%s (%d)", f.Name, len(f.Diagnostics))
+		body := formater.HTMLTable([]string{"analyzer", "line", "problem"}, rows)
+		groups = append(groups, formater.HTMLDetails(title, body, formater.HTMLLevelWarn, false))
+	}
+	return groups
+}
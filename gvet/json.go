@@ -0,0 +1,77 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gvet
+
+import "time"
+
+// JSONReport the json schema of go vet report, see reporter.JSONService.Decode.
+type JSONReport struct {
+	Created  time.Time   `json:"created"`
+	Cost     float32     `json:"cost"` // the seconds of go vet run
+	ExecPath string      `json:"exec_path"`
+	Files    []*JSONFile `json:"files"`
+}
+
+// JSONFile the json schema of a checked go file.
+type JSONFile struct {
+	Name        string           `json:"name"`
+	Diagnostics []JSONDiagnostic `json:"diagnostics"`
+}
+
+// JSONDiagnostic the json schema of a go vet diagnostic.
+type JSONDiagnostic struct {
+	Analyzer string `json:"analyzer"`
+	Line     int    `json:"line"`
+	Col      int    `json:"col"`
+	EndLine  int    `json:"end_line,omitempty"`
+	EndCol   int    `json:"end_col,omitempty"`
+	Message  string `json:"message"`
+}
+
+// JName return the name of report in json.
+func (r *Report) JName() string {
+	return "gvet"
+}
+
+// JReport return the report which is encoded to json, it is decoded by JSONReport.
+func (r *Report) JReport() interface{} {
+	report := &JSONReport{
+		Created:  r.Created,
+		Cost:     r.Cost,
+		ExecPath: r.ExecPath,
+		Files:    []*JSONFile{},
+	}
+	for _, f := range r.Files {
+		jf := &JSONFile{Name: f.Name, Diagnostics: make([]JSONDiagnostic, 0, len(f.Diagnostics))}
+		for _, d := range f.Diagnostics {
+			jf.Diagnostics = append(jf.Diagnostics, JSONDiagnostic{
+				Analyzer: d.Analyzer,
+				Line:     d.Line,
+				Col:      d.Col,
+				EndLine:  d.EndLine,
+				EndCol:   d.EndCol,
+				Message:  d.Message,
+			})
+		}
+		report.Files = append(report.Files, jf)
+	}
+	return report
+}
+
+// JError return the error of go vet.
+func (r *Report) JError() error {
+	return r.SysErr
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ysqi/gcodesharp/config"
+	"github.com/ysqi/gcodesharp/context"
+	"github.com/ysqi/gcodesharp/history"
+	"github.com/ysqi/gcodesharp/reporter"
+
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Print the quality trends of recorded runs",
+	Long: `Print the quality trends of the runs which are recorded by "gcodesharp --record",
+e.g: the coverage, test count and duration of each package, the lint problems and the benchmark results.`,
+	Args: cobra.NoArgs,
+	Run:  runHistory,
+}
+
+var (
+	historyHTML string // save the trend chart to html file
+	historyLast int    // only show the last n runs
+)
+
+func init() {
+	historyCmd.Flags().StringVar(&historyHTML, "html", "", `save the trend chart as a self-contained html file`)
+	historyCmd.Flags().IntVar(&historyLast, "last", 0, `only show the last n runs, zero is all`)
+	rootCmd.AddCommand(historyCmd)
+}
+
+// historyStore return the history store of project.
+func historyStore(cfg *config.Config) *history.Store {
+	dir := cfg.History.Dir
+	if dir == "" {
+		dir = filepath.Join(cfg.Dir(), history.DefaultDir)
+	}
+	return history.Open(dir)
+}
+
+// recordHistory save the metrics of all services to history store.
+func recordHistory(rp *reporter.Reporter, cfg *config.Config) error {
+	metrics, err := rp.Metrics()
+	if err != nil {
+		return err
+	}
+	snap := &history.Snapshot{
+		Time:    time.Now(),
+		Metrics: metrics,
+	}
+	if snap.Commit, err = context.GitCommit(cfg.Dir()); err != nil {
+		log.Printf("[WARN] record history without git commit:%s", err)
+	}
+	file, err := historyStore(cfg).Save(snap)
+	if err != nil {
+		return err
+	}
+	log.Printf("the result is recorded to %s", file)
+	return nil
+}
+
+func runHistory(c *cobra.Command, args []string) {
+	cfg := loadConfig(c)
+	store := historyStore(cfg)
+	snaps, err := store.Load()
+	if err != nil {
+		fatalf("load history:%s", err)
+	}
+	if historyLast > 0 && len(snaps) > historyLast {
+		snaps = snaps[len(snaps)-historyLast:]
+	}
+	if len(snaps) == 0 {
+		fatalf("no history in %s, run gcodesharp with --record to save the result", store.Dir)
+	}
+	first, last := snaps[0], snaps[len(snaps)-1]
+	fmt.Printf("%d runs from %s (%s) to %s (%s)\n\n", len(snaps),
+		first.Time.Format("2006-01-02 15:04"), first.ShortCommit(),
+		last.Time.Format("2006-01-02 15:04"), last.ShortCommit())
+
+	trends := history.Trends(snaps)
+	if err := history.WriteTrends(os.Stdout, trends); err != nil {
+		fatalf("print history:%s", err)
+	}
+	if historyHTML == "" {
+		return
+	}
+	f, err := os.Create(historyHTML)
+	if err != nil {
+		fatalf("create and save history html:%s", err)
+	}
+	defer f.Close()
+	if err := history.WriteHTML(f, snaps, trends); err != nil {
+		fatalf("create and save history html:%s", err)
+	}
+}
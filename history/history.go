@@ -0,0 +1,120 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package history is a local store of the run results, each run is saved as a json snapshot file,
+// so the trend of quality can be reported.
+package history
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// DefaultDir is the default history store dir which is relative to the project root dir.
+const DefaultDir = ".gcodesharp/history"
+
+// Snapshot the metrics of a run, keyed by git commit and time.
+type Snapshot struct {
+	// Commit the git commit hash of HEAD, empty if the project is not in a git repository.
+	Commit  string            `json:"commit,omitempty"`
+	Time    time.Time         `json:"time"`
+	Metrics []reporter.Metric `json:"metrics"`
+}
+
+// ShortCommit return the first 7 chars of commit.
+func (s *Snapshot) ShortCommit() string {
+	if len(s.Commit) > 7 {
+		return s.Commit[:7]
+	}
+	return s.Commit
+}
+
+// Value return the value of metric, ok is false if the metric is not measured.
+func (s *Snapshot) Value(name, target string) (value float64, ok bool) {
+	for _, m := range s.Metrics {
+		if m.Name == name && m.Target == target {
+			return m.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Store a directory of json snapshot files.
+type Store struct {
+	Dir string
+}
+
+// Open return the store of dir, the dir is created when the first snapshot saved.
+func Open(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// Save write the snapshot to a new file of store, the file name look like:
+//
+//	20171021T081520.123456789Z-1a2b3c4.json
+//
+// return the file path.
+func (s *Store) Save(snap *Snapshot) (string, error) {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return "", err
+	}
+	name := snap.Time.UTC().Format("20060102T150405.000000000Z")
+	if c := snap.ShortCommit(); c != "" {
+		name += "-" + c
+	}
+	data, err := json.MarshalIndent(snap, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	file := filepath.Join(s.Dir, name+".json")
+	return file, ioutil.WriteFile(file, data, 0644)
+}
+
+// Load read all snapshots of store, sorted by time.
+// return empty if the store dir does not exist.
+func (s *Store) Load() ([]*Snapshot, error) {
+	files, err := ioutil.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*Snapshot
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		file := filepath.Join(s.Dir, f.Name())
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		snap := &Snapshot{}
+		if err := json.Unmarshal(data, snap); err != nil {
+			return nil, &os.PathError{Op: "decode", Path: file, Err: err}
+		}
+		list = append(list, snap)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Time.Before(list[j].Time) })
+	return list, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gcodesharp-history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := Open(dir + "/history")
+	if list, err := store.Load(); err != nil || len(list) != 0 {
+		t.Fatalf("want empty history of new store, got %d %v", len(list), err)
+	}
+	now := time.Now()
+	// save the newer first, load sorted by time
+	for i, cov := range []float64{60, 50} {
+		snap := &Snapshot{
+			Commit: "1a2b3c4d5e6f",
+			Time:   now.Add(-time.Duration(i) * time.Hour),
+			Metrics: []reporter.Metric{
+				{Name: reporter.MetricCoverage, Target: "github.com/ysqi/com", Value: cov},
+				{Name: reporter.MetricLintProblems, Value: float64(10 - i)},
+			},
+		}
+		file, err := store.Save(snap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(file, "-1a2b3c4.json") {
+			t.Fatalf("want the file name end with the short commit, got %s", file)
+		}
+	}
+	list, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("want 2 snapshots, got %d", len(list))
+	}
+	if v, ok := list[0].Value(reporter.MetricCoverage, "github.com/ysqi/com"); !ok || v != 50 {
+		t.Fatalf("want the older snapshot first with coverage 50, got %g", v)
+	}
+
+	trends := Trends(list)
+	if len(trends) != 2 || trends[0].Name != reporter.MetricCoverage || trends[0].Change() != 10 {
+		t.Fatalf("want the coverage increased 10, got %+v", trends[0])
+	}
+	var buf bytes.Buffer
+	if err := WriteTrends(&buf, trends); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "lint_problems") || !strings.Contains(buf.String(), "+1 ") {
+		t.Fatalf("want the lint problems increased 1, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteHTML(&buf, list, trends); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	if strings.Count(html, "<polyline") != 2 || !strings.Contains(html, "<h2>coverage</h2>") {
+		t.Fatal("want a line chart of each metric")
+	}
+	if strings.Contains(html, "ZgotmplZ") {
+		t.Fatal("want the chart attributes are not escaped as unsafe")
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// the size of chart svg
+const (
+	chartWidth  = 720
+	chartHeight = 240
+	padLeft     = 56
+	padRight    = 16
+	padTop      = 12
+	padBottom   = 32
+)
+
+var chartColors = []string{"#0366d6", "#28a745", "#d73a49", "#6f42c1", "#f66a0a", "#005cc5", "#b08800", "#6a737d"}
+
+type htmlDot struct {
+	X, Y  float64
+	Title string
+}
+
+type htmlLine struct {
+	Target string
+	Color  string
+	Points string
+	Dots   []htmlDot
+}
+
+type htmlLabel struct {
+	X    float64
+	Text string
+}
+
+type htmlChart struct {
+	Name     string
+	Min, Max string
+	Lines    []htmlLine
+	XLabels  []htmlLabel
+}
+
+type htmlPage struct {
+	Created     string
+	Count       int
+	First, Last string
+	Charts      []htmlChart
+}
+
+// WriteHTML write a self-contained html page with a line chart of each metric,
+// each target of metric is a line, the x axis is the snapshots in time order.
+func WriteHTML(w io.Writer, snaps []*Snapshot, list []*Series) error {
+	page := htmlPage{
+		Created: time.Now().Format("2006-01-02 15:04:05"),
+		Count:   len(snaps),
+	}
+	if len(snaps) > 0 {
+		page.First = snaps[0].Time.Format("2006-01-02 15:04")
+		page.Last = snaps[len(snaps)-1].Time.Format("2006-01-02 15:04")
+	}
+	var chart *htmlChart
+	for _, s := range list {
+		if chart == nil || chart.Name != s.Name {
+			page.Charts = append(page.Charts, htmlChart{Name: s.Name})
+			chart = &page.Charts[len(page.Charts)-1]
+		}
+		chart.Lines = append(chart.Lines, htmlLine{Target: s.Target})
+	}
+	// the series of same metric are adjacent
+	i := 0
+	for c := range page.Charts {
+		chart := &page.Charts[c]
+		series := list[i : i+len(chart.Lines)]
+		i += len(chart.Lines)
+		drawChart(chart, series, snaps)
+	}
+	return htmlTpl.Execute(w, page)
+}
+
+// drawChart set the svg coordinates of lines, the y axis is scaled to the value range of all series.
+func drawChart(chart *htmlChart, series []*Series, snaps []*Snapshot) {
+	var all []Point
+	for _, s := range series {
+		all = append(all, s.Points...)
+	}
+	min, max := valueRange(all)
+	chart.Min, chart.Max = fmt.Sprintf("%g", round(min)), fmt.Sprintf("%g", round(max))
+	if max == min {
+		min, max = min-1, max+1
+	}
+	step := float64(chartWidth - padLeft - padRight)
+	if len(snaps) > 1 {
+		step /= float64(len(snaps) - 1)
+	}
+	x := func(i int) float64 { return float64(padLeft) + float64(i)*step }
+	y := func(v float64) float64 {
+		return float64(padTop) + (max-v)/(max-min)*float64(chartHeight-padTop-padBottom)
+	}
+	for i, s := range series {
+		line := &chart.Lines[i]
+		line.Color = chartColors[i%len(chartColors)]
+		var points []string
+		for _, p := range s.Points {
+			dot := htmlDot{X: x(p.Index), Y: y(p.Value)}
+			dot.Title = fmt.Sprintf("%s %s %s: %g", p.Time.Format("2006-01-02 15:04"), p.Commit, s.Target, round(p.Value))
+			points = append(points, fmt.Sprintf("%.1f,%.1f", dot.X, dot.Y))
+			line.Dots = append(line.Dots, dot)
+		}
+		line.Points = strings.Join(points, " ")
+	}
+	// label the first and last snapshot, and some between them
+	every := (len(snaps) + 5) / 6
+	for i, snap := range snaps {
+		if i%every != 0 && i != len(snaps)-1 {
+			continue
+		}
+		text := snap.ShortCommit()
+		if text == "" {
+			text = snap.Time.Format("01-02 15:04")
+		}
+		chart.XLabels = append(chart.XLabels, htmlLabel{X: x(i), Text: text})
+	}
+}
+
+var htmlTpl = template.Must(template.New("history").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>GCodeSharp Quality Trends</title>
+<style>
+body{margin:0;font:14px/1.5 -apple-system,"Segoe UI",Helvetica,Arial,sans-serif;color:#24292e;background:#f6f8fa}
+header{background:#24292e;color:#fff;padding:16px 32px}
+header h1{margin:0;font-size:20px}
+header .meta{color:#aab;font-size:12px}
+main{padding:16px 32px}
+section{background:#fff;border:1px solid #e1e4e8;border-radius:6px;margin-bottom:24px;padding:16px}
+section h2{margin-top:0;font-size:18px;font-family:monospace}
+svg text{font:11px monospace;fill:#586069}
+svg .axis{stroke:#d1d5da}
+.legend{list-style:none;padding:0;margin:8px 0 0;font:12px monospace}
+.legend li{display:inline-block;margin-right:16px}
+.legend .swatch{display:inline-block;width:10px;height:10px;margin-right:4px;vertical-align:middle}
+.empty{color:#6a737d}
+</style>
+</head>
+<body>
+<header>
+<h1>GCodeSharp Quality Trends</h1>
+<div class="meta">{{.Count}} runs{{if .Count}} from {{.First}} to {{.Last}}{{end}} &middot; created at {{.Created}}</div>
+</header>
+<main>
+{{range .Charts}}<section>
+<h2>{{.Name}}</h2>
+<svg width="720" height="240" viewBox="0 0 720 240">
+<line class="axis" x1="56" y1="12" x2="56" y2="208"/><line class="axis" x1="56" y1="208" x2="704" y2="208"/>
+<text x="50" y="16" text-anchor="end">{{.Max}}</text><text x="50" y="208" text-anchor="end">{{.Min}}</text>
+{{range .XLabels}}<text x="{{.X}}" y="226" text-anchor="middle">{{.Text}}</text>
+{{end}}{{range .Lines}}<polyline fill="none" stroke="{{.Color}}" stroke-width="2" points="{{.Points}}"/>
+{{$color := .Color}}{{range .Dots}}<circle cx="{{.X}}" cy="{{.Y}}" r="3" fill="{{$color}}"><title>{{.Title}}</title></circle>
+{{end}}{{end}}</svg>
+<ul class="legend">{{range .Lines}}<li><span class="swatch" style="background:{{.Color}}"></span>{{if .Target}}{{.Target}}{{else}}total{{end}}</li>{{end}}</ul>
+</section>
+{{else}}<p class="empty">no history, run gcodesharp with --record to save the result.</p>{{end}}
+</main>
+</body>
+</html>
+`))
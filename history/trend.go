@@ -0,0 +1,132 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Point a value of metric in a snapshot.
+type Point struct {
+	Index  int // the index of snapshot in the list
+	Commit string
+	Time   time.Time
+	Value  float64
+}
+
+// Series the values of a metric target in snapshots order,
+// the snapshot which does not measure the metric has no point.
+type Series struct {
+	Name   string
+	Target string
+	Points []Point
+}
+
+// Latest return the last point.
+func (s *Series) Latest() Point {
+	return s.Points[len(s.Points)-1]
+}
+
+// Change return the change of latest value from the previous value,
+// it is zero if only one point.
+func (s *Series) Change() float64 {
+	if len(s.Points) < 2 {
+		return 0
+	}
+	return s.Latest().Value - s.Points[len(s.Points)-2].Value
+}
+
+// Trends return the series of each metric target in snapshots, sorted by metric name and target.
+func Trends(snaps []*Snapshot) []*Series {
+	index := map[[2]string]*Series{}
+	var list []*Series
+	for i, snap := range snaps {
+		for _, m := range snap.Metrics {
+			key := [2]string{m.Name, m.Target}
+			s, ok := index[key]
+			if !ok {
+				s = &Series{Name: m.Name, Target: m.Target}
+				index[key] = s
+				list = append(list, s)
+			}
+			s.Points = append(s.Points, Point{Index: i, Commit: snap.ShortCommit(), Time: snap.Time, Value: m.Value})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Target < list[j].Target
+	})
+	return list
+}
+
+// WriteTrends write the trends as a text table, the trend column is the sparkline of the last 20 values.
+func WriteTrends(w io.Writer, list []*Series) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tTARGET\tFIRST\tLATEST\tCHANGE\tTREND")
+	for _, s := range list {
+		change := "-"
+		if len(s.Points) > 1 {
+			change = fmt.Sprintf("%+g", round(s.Change()))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%g\t%g\t%s\t%s\n", s.Name, s.Target,
+			round(s.Points[0].Value), round(s.Latest().Value), change, sparkline(s.Points, 20))
+	}
+	return tw.Flush()
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draw the last n values of points.
+func sparkline(points []Point, n int) string {
+	if len(points) > n {
+		points = points[len(points)-n:]
+	}
+	min, max := valueRange(points)
+	var s strings.Builder
+	for _, p := range points {
+		i := 0
+		if max > min {
+			i = int((p.Value - min) / (max - min) * float64(len(sparks)-1))
+		}
+		s.WriteRune(sparks[i])
+	}
+	return s.String()
+}
+
+func valueRange(points []Point) (min, max float64) {
+	for i, p := range points {
+		if i == 0 || p.Value < min {
+			min = p.Value
+		}
+		if i == 0 || p.Value > max {
+			max = p.Value
+		}
+	}
+	return
+}
+
+// round the value to 3 decimal places.
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
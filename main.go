@@ -29,7 +29,7 @@ func init() {
 	c, err := context.New()
 	if err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		os.Exit(exitCrashed)
 	}
 	ctx = c
 }
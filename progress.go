@@ -0,0 +1,236 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ysqi/gcodesharp/reporter"
+)
+
+// barWidth the number of chars of progress bar.
+const barWidth = 24
+
+// progress prints the events of reporter and services.
+// it draws a progress bar of each service on terminal, otherwise prints the events line by line, e.g: CI logs.
+// only the failures are printed in quiet mode.
+type progress struct {
+	w      io.Writer
+	logger *log.Logger
+	tty    bool
+	quiet  bool
+
+	services []*serviceProgress // in started order
+	drawn    int                // the number of bar lines on terminal
+
+	sync.Mutex
+}
+
+// serviceProgress is the progress of a service.
+type serviceProgress struct {
+	name     string
+	queued   int // the number of packages submitted
+	done     int // the number of packages checked
+	failed   int // the number of failed packages
+	findings int
+	started  time.Time
+	cost     time.Duration
+	finished bool
+	err      error
+}
+
+// newProgress return the progress printer of file,
+// the progress bars are drawn if the file is a terminal and not quiet.
+func newProgress(f *os.File, quiet bool) *progress {
+	return &progress{
+		w:      f,
+		logger: log.New(f, "", log.Ltime),
+		tty:    !quiet && isTerminal(f),
+		quiet:  quiet,
+	}
+}
+
+// isTerminal return true if the file is a terminal which supports the ANSI escape codes.
+func isTerminal(f *os.File) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// start subscribe the events, and the log is printed above the bars on terminal.
+func (p *progress) start(bus *reporter.EventBus) {
+	bus.Subscribe(p.handle)
+	if p.tty {
+		log.SetOutput(p)
+	}
+}
+
+// stop leave the last bars on terminal and restore the log output.
+func (p *progress) stop() {
+	p.Lock()
+	defer p.Unlock()
+	if p.tty {
+		p.draw()
+		p.drawn = 0
+		log.SetOutput(os.Stderr)
+	}
+}
+
+// Write print the log line above the bars.
+func (p *progress) Write(b []byte) (int, error) {
+	p.Lock()
+	defer p.Unlock()
+	p.clear()
+	n, err := p.w.Write(b)
+	p.draw()
+	return n, err
+}
+
+func (p *progress) service(name string) *serviceProgress {
+	for _, sp := range p.services {
+		if sp.name == name {
+			return sp
+		}
+	}
+	sp := &serviceProgress{name: name}
+	p.services = append(p.services, sp)
+	return sp
+}
+
+func (p *progress) handle(e reporter.Event) {
+	p.Lock()
+	defer p.Unlock()
+	var (
+		sp    = p.service(e.Service)
+		lines []string
+		// the line is printed if it is a failure or not quiet, the raw output is not printed on terminal.
+		add = func(failure bool, format string, args ...interface{}) {
+			if failure || (!p.quiet && !p.tty) {
+				lines = append(lines, fmt.Sprintf(format, args...))
+			}
+		}
+	)
+	switch e.Kind {
+	case reporter.EventServiceStarted:
+		sp.started = e.Time
+		add(false, "%s: started", sp.name)
+	case reporter.EventServiceFinished:
+		sp.finished = true
+		sp.err = e.Err
+		sp.cost = e.Time.Sub(sp.started)
+		if e.Err != nil {
+			add(true, "[ERROR] %s: %s", sp.name, e.Err)
+		} else {
+			add(false, "%s: %s", sp.name, sp.summary())
+		}
+	case reporter.EventPackageQueued:
+		sp.queued++
+	case reporter.EventPackageFinished:
+		sp.done++
+		if e.Failed {
+			sp.failed++
+			add(true, "%s: FAIL %s", sp.name, e.Package)
+		}
+	case reporter.EventTestFailed:
+		add(true, "--- FAIL: %s (%s)", e.Test, e.Package)
+		if out := strings.TrimSpace(e.Output); out != "" {
+			add(true, "    %s", strings.Replace(out, "\n", "\n    ", -1))
+		}
+	case reporter.EventFinding:
+		sp.findings++
+		f := e.Finding
+		add(false, "%s: [%s/%s] %s", f.Position(), f.Tool, f.Rule, f.Message)
+	case reporter.EventOutput:
+		add(false, "%s", e.Output)
+	case reporter.EventMessage:
+		add(false, "%s: %s", sp.name, e.Output)
+	case reporter.EventWarning:
+		add(true, "[WARN] %s: %s", sp.name, e.Output)
+	}
+
+	if !p.tty {
+		for _, line := range lines {
+			p.logger.Println(line)
+		}
+		return
+	}
+	if len(lines) == 0 && (e.Kind == reporter.EventOutput || e.Kind == reporter.EventTestPassed) {
+		// the bars are not changed
+		return
+	}
+	p.clear()
+	for _, line := range lines {
+		fmt.Fprintln(p.w, line)
+	}
+	p.draw()
+}
+
+// clear remove the bars on terminal.
+func (p *progress) clear() {
+	if p.tty && p.drawn > 0 {
+		// move up and clear to the end of screen
+		fmt.Fprintf(p.w, "\x1b[%dA\x1b[J", p.drawn)
+		p.drawn = 0
+	}
+}
+
+// draw the bar of each service on terminal.
+func (p *progress) draw() {
+	if !p.tty {
+		return
+	}
+	p.clear()
+	for _, sp := range p.services {
+		fmt.Fprintln(p.w, sp.bar())
+	}
+	p.drawn = len(p.services)
+}
+
+// bar return the progress bar, e.g: gtest  [############------------] 5/10 1 failed
+func (sp *serviceProgress) bar() string {
+	filled := 0
+	if sp.queued > 0 {
+		filled = barWidth * sp.done / sp.queued
+	}
+	return fmt.Sprintf("%-7s [%s%s] %s", sp.name,
+		strings.Repeat("#", filled), strings.Repeat("-", barWidth-filled), sp.summary())
+}
+
+// summary return the packages and failures, e.g: 5/10 packages, 1 failed, 3 findings
+func (sp *serviceProgress) summary() string {
+	list := []string{fmt.Sprintf("%d/%d packages", sp.done, sp.queued)}
+	if sp.failed > 0 {
+		list = append(list, fmt.Sprintf("%d failed", sp.failed))
+	}
+	if sp.findings > 0 {
+		list = append(list, fmt.Sprintf("%d findings", sp.findings))
+	}
+	switch {
+	case sp.err != nil:
+		list = append(list, "error")
+	case sp.finished:
+		list = append(list, fmt.Sprintf("done in %.1fs", sp.cost.Seconds()))
+	}
+	return strings.Join(list, ", ")
+}
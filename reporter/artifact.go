@@ -0,0 +1,177 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Node a service dependency interface.
+// reporter service need implement if other services depend on it or it depends on others,
+// the service runs after all of its dependencies done, the service without dependency runs at once.
+type Node interface {
+	// Name return the unique name of service, e.g: gtest
+	Name() string
+	// Depends return the names of services which must be done before it runs.
+	// the service which is not registered is ignored, e.g: it is not selected by --tool.
+	Depends() []string
+}
+
+// ArtifactProducer a artifact produce interface.
+// reporter service need implement if its outputs are used by the dependent services.
+type ArtifactProducer interface {
+	// Produce put the outputs to artifacts, it is called after the service done without error.
+	Produce(a *Artifacts)
+}
+
+// ArtifactConsumer a artifact consume interface.
+// reporter service need implement if it uses the outputs of its dependencies.
+type ArtifactConsumer interface {
+	// Consume read the outputs of dependencies, it is called before the service runs,
+	// the service is failed and not run if error is returned.
+	Consume(a *Artifacts) error
+}
+
+// The artifacts of builtin services, the comment is the type of artifact.
+const (
+	// ArtifactBuildErrors is BuildErrors, produced by gbuild.
+	ArtifactBuildErrors = "build_errors"
+	// ArtifactCoverProfiles is []*coverage.Profile of all tested packages, produced by gtest.
+	ArtifactCoverProfiles = "cover_profiles"
+)
+
+// BuildErrors is the build error of packages which can not be compiled, the key is import path.
+type BuildErrors map[string]string
+
+// ErrArtifactNotFound the artifact is not produced, e.g: its producer is not registered.
+var ErrArtifactNotFound = errors.New("artifact not found")
+
+// Artifacts is the typed outputs of services which are passed to the dependent services.
+type Artifacts struct {
+	items map[string]interface{}
+	sync.RWMutex
+}
+
+// NewArtifacts return a empty artifacts.
+func NewArtifacts() *Artifacts {
+	return &Artifacts{items: make(map[string]interface{})}
+}
+
+// Put add the artifact, the artifact with same name is replaced.
+func (a *Artifacts) Put(name string, v interface{}) {
+	a.Lock()
+	defer a.Unlock()
+	a.items[name] = v
+}
+
+// Get store the artifact to the value which v points to, e.g:
+//
+//	var errs reporter.BuildErrors
+//	err := a.Get(reporter.ArtifactBuildErrors, &errs)
+//
+// return ErrArtifactNotFound if it is not put, or error if its type is not assignable to v.
+func (a *Artifacts) Get(name string, v interface{}) error {
+	a.RLock()
+	item, ok := a.items[name]
+	a.RUnlock()
+	if !ok {
+		return ErrArtifactNotFound
+	}
+	dst := reflect.ValueOf(v)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return fmt.Errorf("get artifact %s: need a non-nil pointer, got %T", name, v)
+	}
+	src := reflect.ValueOf(item)
+	if !src.Type().AssignableTo(dst.Elem().Type()) {
+		return fmt.Errorf("get artifact %s: type %T is not assignable to %s", name, item, dst.Elem().Type())
+	}
+	dst.Elem().Set(src)
+	return nil
+}
+
+// Artifacts return the artifacts of last run.
+func (r *Reporter) Artifacts() *Artifacts {
+	r.RLock()
+	defer r.RUnlock()
+	return r.artifacts
+}
+
+// dependencies return the index of dependencies of each service,
+// error if the name of services is repeated or the dependencies has a cycle.
+func dependencies(services []Service) ([][]int, error) {
+	names := make(map[string]int, len(services))
+	for i, s := range services {
+		if n, ok := s.(Node); ok {
+			if _, exists := names[n.Name()]; exists {
+				return nil, fmt.Errorf("repeated service name %q", n.Name())
+			}
+			names[n.Name()] = i
+		}
+	}
+	deps := make([][]int, len(services))
+	for i, s := range services {
+		n, ok := s.(Node)
+		if !ok {
+			continue
+		}
+		for _, name := range n.Depends() {
+			if j, ok := names[name]; ok && j != i {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+
+	// find cycle by depth first search, the state is 1 if visiting and 2 if visited.
+	state := make([]int, len(services))
+	var path []int
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case 1:
+			var cycle []string
+			for k := len(path) - 1; k >= 0; k-- {
+				cycle = append([]string{services[path[k]].(Node).Name()}, cycle...)
+				if path[k] == i {
+					break
+				}
+			}
+			cycle = append(cycle, services[i].(Node).Name())
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		case 2:
+			return nil
+		}
+		state[i] = 1
+		path = append(path, i)
+		for _, j := range deps[i] {
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = 2
+		return nil
+	}
+	for i := range services {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return deps, nil
+}
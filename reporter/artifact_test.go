@@ -0,0 +1,145 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	gocontext "context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// NodeService is a service of dependency graph, it records the order of services.
+type NodeService struct {
+	name    string
+	depends []string
+	order   *[]string
+	lock    *sync.Mutex
+}
+
+func (s *NodeService) Name() string      { return s.name }
+func (s *NodeService) Depends() []string { return s.depends }
+func (s *NodeService) Run(ctx gocontext.Context) error {
+	// the slow service must still be done before its dependent services.
+	time.Sleep(10 * time.Millisecond)
+	s.lock.Lock()
+	*s.order = append(*s.order, s.name)
+	s.lock.Unlock()
+	return nil
+}
+
+type BuildNode struct{ *NodeService }
+
+func (s *BuildNode) Produce(a *Artifacts) {
+	a.Put(ArtifactBuildErrors, BuildErrors{"a/b": "undefined: x"})
+}
+
+type TestNode struct {
+	*NodeService
+	errs BuildErrors
+}
+
+func (s *TestNode) Consume(a *Artifacts) error {
+	return a.Get(ArtifactBuildErrors, &s.errs)
+}
+
+type LintNode struct{ *NodeService }
+
+func TestReporter_Depends(t *testing.T) {
+	var (
+		order []string
+		lock  sync.Mutex
+	)
+	node := func(name string, depends ...string) *NodeService {
+		return &NodeService{name: name, depends: depends, order: &order, lock: &lock}
+	}
+	test := &TestNode{NodeService: node("test", "build", "cover")}
+	r, _ := New(&ServiceContext{})
+	// the dependent service is registered before its dependency,
+	// and the cover service is not registered.
+	r.Register(func(ctx *ServiceContext) (Service, error) { return test, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &BuildNode{node("build")}, nil })
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(order, ",") != "build,test" {
+		t.Fatalf("want test runs after build, got %v", order)
+	}
+	if test.errs["a/b"] != "undefined: x" {
+		t.Fatalf("want the build errors of dependency, got %v", test.errs)
+	}
+}
+
+func TestReporter_DependsCycle(t *testing.T) {
+	var (
+		order []string
+		lock  sync.Mutex
+	)
+	node := func(name string, depends ...string) *NodeService {
+		return &NodeService{name: name, depends: depends, order: &order, lock: &lock}
+	}
+	r, _ := New(&ServiceContext{})
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &BuildNode{node("build", "lint")}, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &TestNode{NodeService: node("test", "build")}, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &LintNode{node("lint", "test")}, nil })
+	err := r.Start(gocontext.Background())
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle: build -> lint -> test -> build") {
+		t.Fatalf("want dependency cycle error, got %v", err)
+	}
+}
+
+func TestReporter_ConsumeFailed(t *testing.T) {
+	var (
+		order []string
+		lock  sync.Mutex
+	)
+	test := &TestNode{NodeService: &NodeService{name: "test", depends: []string{"build"}, order: &order, lock: &lock}}
+	r, _ := New(&ServiceContext{})
+	r.Register(func(ctx *ServiceContext) (Service, error) { return test, nil })
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	err := r.Wait()
+	if err == nil || !strings.Contains(err.Error(), ErrArtifactNotFound.Error()) {
+		t.Fatalf("want artifact not found error, got %v", err)
+	}
+	if len(order) != 0 {
+		t.Fatalf("want the service is not run, got %v", order)
+	}
+}
+
+func TestArtifacts_Get(t *testing.T) {
+	a := NewArtifacts()
+	var errs BuildErrors
+	if err := a.Get(ArtifactBuildErrors, &errs); err != ErrArtifactNotFound {
+		t.Fatalf("want not found error, got %v", err)
+	}
+	a.Put(ArtifactBuildErrors, BuildErrors{"a": "b"})
+	if err := a.Get(ArtifactBuildErrors, &errs); err != nil || errs["a"] != "b" {
+		t.Fatalf("want build errors, got %v %v", errs, err)
+	}
+	var wrong []string
+	if err := a.Get(ArtifactBuildErrors, &wrong); err == nil {
+		t.Fatal("want type error")
+	}
+	if err := a.Get(ArtifactBuildErrors, errs); err == nil {
+		t.Fatal("want pointer error")
+	}
+}
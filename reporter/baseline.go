@@ -0,0 +1,218 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// BaselineVersion is the version of baseline file format.
+const BaselineVersion = 1
+
+// FindingSuppressor a finding suppress interface.
+// reporter service need implement if its findings can be suppressed by baseline,
+// the service must remove the suppressed item from its report, so all outputs and gates ignore it.
+// the failed test is never suppressed, so the test service does not implement it.
+type FindingSuppressor interface {
+	FindingProvider
+	// Suppress remove the item from report if drop return true for its finding.
+	Suppress(drop func(Finding) bool)
+}
+
+// BaselineEntry is a known finding in baseline.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Tool        string `json:"tool"`
+	Rule        string `json:"rule"`
+	File        string `json:"file,omitempty"` // the slash path relative to the project root
+	Line        int    `json:"line,omitempty"` // the line when saved, only for human reading
+	Snippet     string `json:"snippet,omitempty"`
+	Message     string `json:"message"`
+}
+
+// Position return the position text, e.g: a.go:1
+func (e BaselineEntry) Position() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+	return e.File
+}
+
+// Baseline is the known findings of project, the finding in baseline is suppressed,
+// so only the new finding is reported.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Created  time.Time       `json:"created"`
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// NewBaseline create the baseline of findings, the file path is relative to root dir.
+func NewBaseline(findings []Finding, root string) *Baseline {
+	b := &Baseline{
+		Version:  BaselineVersion,
+		Created:  time.Now(),
+		Findings: []BaselineEntry{},
+	}
+	for _, f := range findings {
+		file := f.File
+		if root != "" && filepath.IsAbs(file) {
+			if rel, err := filepath.Rel(root, file); err == nil {
+				file = rel
+			}
+		}
+		b.Findings = append(b.Findings, BaselineEntry{
+			Fingerprint: f.Fingerprint,
+			Tool:        f.Tool,
+			Rule:        f.Rule,
+			File:        filepath.ToSlash(file),
+			Line:        f.Line,
+			Snippet:     f.Snippet,
+			Message:     f.Message,
+		})
+	}
+	return b
+}
+
+// ReadBaseline read the baseline json.
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	b := &Baseline{}
+	if err := json.NewDecoder(r).Decode(b); err != nil {
+		return nil, fmt.Errorf("decode baseline:%s", err)
+	}
+	if b.Version > BaselineVersion {
+		return nil, fmt.Errorf("baseline version %d is not supported, upgrade gcodesharp", b.Version)
+	}
+	return b, nil
+}
+
+// Write write the baseline as indented json.
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// BaselineResult is the result of applying baseline.
+type BaselineResult struct {
+	// Suppressed is the number of findings which are in baseline.
+	Suppressed int
+	// Fixed is the baseline entries which are not found any more.
+	Fixed []BaselineEntry
+}
+
+// Baseline return the baseline of current findings,
+// only the findings of FindingSuppressor service are contained.
+// it must be called after the reporter done.
+func (r *Reporter) Baseline() (*Baseline, error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return nil, ErrIsRunning
+	}
+	tools := r.suppressors()
+	_, list := r.findings()
+	var known []Finding
+	for _, f := range list {
+		if _, ok := tools[f.Tool]; ok {
+			known = append(known, f)
+		}
+	}
+	return NewBaseline(known, r.rootDir()), nil
+}
+
+// ApplyBaseline remove the findings which are in baseline from the report of services,
+// so they are not in any output and not counted by gates.
+// the baseline entry is fixed if its tool is run but the finding is not found.
+// it must be called after the reporter done and before the outputs.
+func (r *Reporter) ApplyBaseline(b *Baseline) (*BaselineResult, error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return nil, ErrIsRunning
+	}
+	known := make(map[string]struct{}, len(b.Findings))
+	for _, e := range b.Findings {
+		known[e.Fingerprint] = struct{}{}
+	}
+	tools := r.suppressors()
+	_, list := r.findings()
+
+	result := &BaselineResult{}
+	found := map[string]struct{}{}
+	// the findings to drop, the value is the number of same findings
+	drops := map[findingKey]int{}
+	for _, f := range list {
+		if _, ok := tools[f.Tool]; !ok {
+			continue
+		}
+		found[f.Fingerprint] = struct{}{}
+		if _, ok := known[f.Fingerprint]; ok {
+			drops[keyOf(f)]++
+			result.Suppressed++
+		}
+	}
+	for _, e := range b.Findings {
+		if _, ok := tools[e.Tool]; !ok {
+			continue
+		}
+		if _, ok := found[e.Fingerprint]; !ok {
+			result.Fixed = append(result.Fixed, e)
+		}
+	}
+
+	for _, s := range r.services[false] {
+		fs, ok := s.(FindingSuppressor)
+		if !ok {
+			continue
+		}
+		fs.Suppress(func(f Finding) bool {
+			k := keyOf(f)
+			if drops[k] == 0 {
+				return false
+			}
+			drops[k]--
+			return true
+		})
+	}
+	return result, nil
+}
+
+// suppressors return the tool names of FindingSuppressor service.
+func (r *Reporter) suppressors() map[string]struct{} {
+	tools := map[string]struct{}{}
+	for _, s := range r.services[false] {
+		if fs, ok := s.(FindingSuppressor); ok {
+			tools[fs.Tool()] = struct{}{}
+		}
+	}
+	return tools
+}
+
+// findingKey identify the finding which is returned by service,
+// the fingerprint is not used because it is set by reporter.
+type findingKey struct {
+	tool, rule, file string
+	line, col        int
+	message          string
+}
+
+func keyOf(f Finding) findingKey {
+	return findingKey{f.Tool, f.Rule, f.File, f.Line, f.Col, f.Message}
+}
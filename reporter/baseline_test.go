@@ -0,0 +1,151 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"bytes"
+	gocontext "context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ysqi/gcodesharp/config"
+)
+
+type VetService struct {
+	HelloService
+	list []Finding
+}
+
+func (s *VetService) Tool() string {
+	return "govet"
+}
+
+func (s *VetService) Findings() []Finding {
+	return s.list
+}
+
+func (s *VetService) Suppress(drop func(Finding) bool) {
+	var kept []Finding
+	for _, f := range s.list {
+		if !drop(f) {
+			kept = append(kept, f)
+		}
+	}
+	s.list = kept
+}
+
+func runFindings(t *testing.T, services ...Service) *Reporter {
+	// the project root is /p
+	r, err := New(&ServiceContext{Config: &config.Config{File: "/p/" + config.FileName}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range services {
+		s := s
+		r.Register(func(ctx *ServiceContext) (Service, error) { return s, nil })
+	}
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+	return r
+}
+
+func TestSnippetFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gcodesharp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "a.go")
+	write := func(code string) {
+		if err := ioutil.WriteFile(file, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fingerprint := func(line int) string {
+		r := runFindings(t, &VetService{list: []Finding{{Tool: "govet", Rule: "assign", File: file, Line: line, Message: "self-assignment of x to x"}}})
+		list, err := r.Findings()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return list[0].Fingerprint
+	}
+
+	write("package a\n\nfunc A() {\n\tx = x\n}\n")
+	want := fingerprint(4)
+	write("package a\n\n// A is a func.\nfunc A() {\n\t\tx  =  x\n}\n")
+	if got := fingerprint(5); got != want {
+		t.Fatalf("want same fingerprint if the line shifted, got %s and %s", want, got)
+	}
+	write("package a\n\nfunc A() {\n\ty = y\n}\n")
+	if got := fingerprint(4); got == want {
+		t.Fatal("want different fingerprint if the code changed")
+	}
+}
+
+func TestReporter_ApplyBaseline(t *testing.T) {
+	old := &VetService{list: []Finding{
+		{Tool: "govet", Rule: "assign", File: "/p/a.go", Line: 4, Message: "self-assignment of x to x"},
+		{Tool: "govet", Rule: "printf", File: "/p/b.go", Line: 9, Message: "wrong type of %d"},
+	}}
+	b, err := runFindings(t, old, &FindingService{}).Baseline()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Findings) != 2 || b.Version != BaselineVersion {
+		t.Fatalf("want 2 findings of suppressible tool in baseline, got %+v", b)
+	}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if b, err = ReadBaseline(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// the printf finding is fixed and a new finding is added
+	now := &VetService{list: []Finding{
+		{Tool: "govet", Rule: "assign", File: "/p/a.go", Line: 4, Message: "self-assignment of x to x"},
+		{Tool: "govet", Rule: "assign", File: "/p/c.go", Line: 2, Message: "self-assignment of y to y"},
+	}}
+	r := runFindings(t, now, &FindingService{})
+	result, err := r.ApplyBaseline(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Suppressed != 1 {
+		t.Fatalf("want 1 finding suppressed, got %d", result.Suppressed)
+	}
+	if len(result.Fixed) != 1 || result.Fixed[0].Rule != "printf" || result.Fixed[0].Position() != "b.go:9" {
+		t.Fatalf("want the printf finding fixed, got %+v", result.Fixed)
+	}
+	list, err := r.Findings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the golint findings is not suppressible
+	if len(list) != 4 || list[len(list)-1].File != "/p/c.go" {
+		t.Fatalf("want 3 golint findings and the new govet finding, got %+v", list)
+	}
+
+	if _, err := ReadBaseline(bytes.NewBufferString(`{"version":99}`)); err == nil {
+		t.Fatal("want error for unsupported baseline version")
+	}
+}
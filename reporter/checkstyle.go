@@ -0,0 +1,92 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+// OutputCheckstyle write the findings of file as checkstyle xml.
+// each go file is a <file>, and each finding is a <error>,
+// the file need format is split to one <error> per diff hunk.
+func (r *Reporter) OutputCheckstyle(noXMLHeader bool, w io.Writer) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return ErrIsRunning
+	}
+	_, list := r.findings()
+	report := formater.Checkstyle{Version: formater.CheckstyleVersion}
+	index := map[string]int{}
+	for _, f := range list {
+		// the failed package or test is not about a file
+		if f.File == "" {
+			continue
+		}
+		i, ok := index[f.File]
+		if !ok {
+			i = len(report.Files)
+			index[f.File] = i
+			report.Files = append(report.Files, formater.CheckstyleFile{Name: f.File})
+		}
+		report.Files[i].Errors = append(report.Files[i].Errors, checkstyleErrors(f)...)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if !noXMLHeader {
+		w.Write([]byte(xml.Header))
+	}
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	w.Write([]byte("\n"))
+	return enc.Flush()
+}
+
+func checkstyleErrors(f Finding) []formater.CheckstyleError {
+	source := f.Tool
+	if f.Rule != "" && f.Rule != f.Tool {
+		source += "." + f.Rule
+	}
+	severity := string(f.Severity)
+	if severity == "" {
+		severity = string(SeverityWarning)
+	}
+	hunks := SplitHunks(f.Fix)
+	if len(hunks) == 0 {
+		return []formater.CheckstyleError{{
+			Line:     f.Line,
+			Column:   f.Col,
+			Severity: severity,
+			Source:   source,
+			Message:  f.Message,
+		}}
+	}
+	list := make([]formater.CheckstyleError, 0, len(hunks))
+	for _, h := range hunks {
+		list = append(list, formater.CheckstyleError{
+			Line:     h.Line,
+			Severity: severity,
+			Source:   source,
+			Message:  f.Message + "\n" + h.Text,
+		})
+	}
+	return list
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/xml"
+	"testing"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+func TestReporter_OutputCheckstyle(t *testing.T) {
+	r, err := New(&ServiceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := "--- a.go.orig\n+++ a.go\n@@ -3,3 +3,3 @@\n import \"fmt\"\n-func A(){\n+func A() {\n \n@@ -10,2 +10,2 @@\n-var b=1\n+var b = 1\n \n"
+	services := []Service{
+		&FmtService{SARIFService{tool: "gofmt", findings: []Finding{{Tool: "gofmt", Rule: "gofmt", Severity: SeverityWarning, File: "/p/a.go", Line: 3, Message: "file is not gofmt-ed", Fix: diff}}}},
+		&LintService{SARIFService{tool: "golint", findings: []Finding{
+			{Tool: "golint", Rule: "golint", Severity: SeverityWarning, File: "/p/a.go", Line: 5, Col: 1, Message: "exported func A should have comment"},
+			{Tool: "golint", Rule: "golint", Severity: SeverityWarning, File: "/p/b.go", Line: 7, Col: 2, Message: "don't use underscores"},
+		}}},
+		&GoTestService{SARIFService{tool: "gotest", findings: []Finding{{Tool: "gotest", Rule: "build", Severity: SeverityError, Message: "p: build failed"}}}},
+	}
+	for _, s := range services {
+		s := s
+		r.Register(func(ctx *ServiceContext) (Service, error) { return s, nil })
+	}
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+
+	var buf bytes.Buffer
+	if err := r.OutputCheckstyle(false, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var report formater.Checkstyle
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 2 {
+		t.Fatalf("want 2 files, got %d", len(report.Files))
+	}
+	a := report.Files[0]
+	if a.Name != "/p/a.go" || len(a.Errors) != 3 {
+		t.Fatalf("want 3 errors in a.go, got %+v", a)
+	}
+	// the gofmt diff is split to hunks, the line is the first changed line.
+	if a.Errors[0].Source != "gofmt" || a.Errors[0].Line != 4 || a.Errors[1].Line != 10 {
+		t.Fatalf("want gofmt error at line 4 and 10, got %+v", a.Errors)
+	}
+	if e := a.Errors[2]; e.Source != "golint" || e.Line != 5 || e.Column != 1 || e.Severity != "warning" {
+		t.Fatalf("want golint error at 5:1, got %+v", e)
+	}
+}
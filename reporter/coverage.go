@@ -0,0 +1,186 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ysqi/gcodesharp/coverage"
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+// CoverageProvider a cover profile provide interface.
+// reporter service need implement if it can provide the line coverage.
+type CoverageProvider interface {
+	CoverProfiles() []*coverage.Profile
+}
+
+// coverProfiles return the merged cover profiles of all services.
+func (r *Reporter) coverProfiles() []*coverage.Profile {
+	var list [][]*coverage.Profile
+	for _, s := range r.services[false] {
+		cp, ok := s.(CoverageProvider)
+		if !ok {
+			continue
+		}
+		list = append(list, cp.CoverProfiles())
+	}
+	return coverage.Merge(list...)
+}
+
+// OutputCobertura write the line coverage as cobertura xml,
+// each go package is a <package>, each go file is a <class> and each function is a <method>.
+func (r *Reporter) OutputCobertura(noXMLHeader bool, w io.Writer) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return ErrIsRunning
+	}
+	root := r.rootDir()
+	report := formater.Cobertura{
+		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+		Sources:   []string{root},
+	}
+	pkgIndex := map[string]int{}
+	pkgLines := map[string][2]int{}
+	for _, p := range r.coverProfiles() {
+		pkgName := path.Dir(p.FileName)
+		i, ok := pkgIndex[pkgName]
+		if !ok {
+			i = len(report.Packages)
+			pkgIndex[pkgName] = i
+			report.Packages = append(report.Packages, formater.CoberturaPackage{Name: pkgName})
+		}
+		class := formater.CoberturaClass{
+			Name:     path.Base(p.FileName),
+			Filename: relPath(root, p),
+		}
+		lines := p.Lines()
+		covered := 0
+		for _, l := range lines {
+			if l.Hits > 0 {
+				covered++
+			}
+			class.Lines = append(class.Lines, formater.CoberturaLine{Number: l.Line, Hits: l.Hits})
+		}
+		class.LineRate = rate(covered, len(lines))
+		if p.Path != "" {
+			// the function coverage need the source file
+			if funcs, err := p.Funcs(); err == nil {
+				for _, f := range funcs {
+					m := formater.CoberturaMethod{Name: f.Name, LineRate: f.Percent() / 100}
+					for _, l := range f.Lines {
+						m.Lines = append(m.Lines, formater.CoberturaLine{Number: l.Line, Hits: l.Hits})
+					}
+					class.Methods = append(class.Methods, m)
+				}
+			}
+		}
+		report.Packages[i].Classes = append(report.Packages[i].Classes, class)
+
+		n := pkgLines[pkgName]
+		pkgLines[pkgName] = [2]int{n[0] + covered, n[1] + len(lines)}
+		report.LinesCovered += covered
+		report.LinesValid += len(lines)
+	}
+	for i, pkg := range report.Packages {
+		n := pkgLines[pkg.Name]
+		report.Packages[i].LineRate = rate(n[0], n[1])
+	}
+	report.LineRate = rate(report.LinesCovered, report.LinesValid)
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if !noXMLHeader {
+		w.Write([]byte(xml.Header))
+		w.Write([]byte(formater.CoberturaDTD + "\n"))
+	}
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	w.Write([]byte("\n"))
+	return enc.Flush()
+}
+
+// OutputLCOV write the line and function coverage as lcov tracefile.
+// the format see: http://ltp.sourceforge.net/coverage/lcov/geninfo.1.php
+func (r *Reporter) OutputLCOV(w io.Writer) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return ErrIsRunning
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "TN:")
+	for _, p := range r.coverProfiles() {
+		file := p.Path
+		if file == "" {
+			file = p.FileName
+		}
+		fmt.Fprintf(bw, "SF:%s\n", file)
+		if p.Path != "" {
+			if funcs, err := p.Funcs(); err == nil {
+				hit := 0
+				for _, f := range funcs {
+					fmt.Fprintf(bw, "FN:%d,%s\n", f.StartLine, f.Name)
+				}
+				for _, f := range funcs {
+					fmt.Fprintf(bw, "FNDA:%d,%s\n", f.Hits, f.Name)
+					if f.Hits > 0 {
+						hit++
+					}
+				}
+				fmt.Fprintf(bw, "FNF:%d\nFNH:%d\n", len(funcs), hit)
+			}
+		}
+		lines := p.Lines()
+		hit := 0
+		for _, l := range lines {
+			fmt.Fprintf(bw, "DA:%d,%d\n", l.Line, l.Hits)
+			if l.Hits > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(bw, "LF:%d\nLH:%d\nend_of_record\n", len(lines), hit)
+	}
+	return bw.Flush()
+}
+
+// relPath return the file path relative to root,
+// return the file name of profile if the file is not in root.
+func relPath(root string, p *coverage.Profile) string {
+	if p.Path == "" {
+		return p.FileName
+	}
+	if rel, err := filepath.Rel(root, p.Path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(p.Path)
+}
+
+func rate(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total)
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/ysqi/gcodesharp/coverage"
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+type CoverService struct {
+	HelloService
+}
+
+func (c *CoverService) CoverProfiles() []*coverage.Profile {
+	return []*coverage.Profile{{
+		FileName: "example.com/a/a.go",
+		Mode:     "set",
+		Blocks: []coverage.Block{
+			{StartLine: 3, StartCol: 14, EndLine: 4, EndCol: 10, NumStmt: 1, Count: 1},
+			{StartLine: 5, StartCol: 2, EndLine: 5, EndCol: 10, NumStmt: 1, Count: 0},
+		},
+	}}
+}
+
+func TestReporter_OutputCoverage(t *testing.T) {
+	r, err := New(&ServiceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &CoverService{}, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &HelloService{}, nil })
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+
+	var buf bytes.Buffer
+	if err := r.OutputLCOV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "TN:\nSF:example.com/a/a.go\nDA:3,1\nDA:4,1\nDA:5,0\nLF:3\nLH:2\nend_of_record\n"
+	if buf.String() != want {
+		t.Fatalf("want lcov:\n%s\ngot:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := r.OutputCobertura(true, &buf); err != nil {
+		t.Fatal(err)
+	}
+	var report formater.Cobertura
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.LinesValid != 3 || report.LinesCovered != 2 || len(report.Packages) != 1 {
+		t.Fatalf("want 2 of 3 lines covered in a package, got %+v", report)
+	}
+	class := report.Packages[0].Classes[0]
+	if report.Packages[0].Name != "example.com/a" || class.Filename != "example.com/a/a.go" || len(class.Lines) != 3 {
+		t.Fatalf("want class a.go with 3 lines, got %+v", class)
+	}
+	if !strings.HasPrefix(buf.String(), "<coverage") {
+		t.Fatal("want no xml header")
+	}
+}
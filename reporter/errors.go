@@ -42,7 +42,7 @@ func (r ReportActionError) Error() string {
 		str += r.err.Error() + "."
 	}
 	for k, err := range r.services {
-		str += fmt.Sprintf("service %q:%s,", k.Name(), err.Error())
+		str += fmt.Sprintf("service %q:%s,", k.String(), err.Error())
 	}
 	return str
 }
@@ -0,0 +1,126 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// EventKind is the kind of event.
+type EventKind int
+
+// The kinds of event which are published by reporter and services.
+const (
+	EventServiceStarted  EventKind = iota + 1 // the service starts to run
+	EventServiceFinished                      // the service is done, Err is set if it failed
+	EventPackageQueued                        // the package is submitted to scheduler
+	EventPackageStarted                       // the service starts to check the package
+	EventPackageFinished                      // the package is checked, Failed is set if it has problem
+	EventTestPassed                           // the test is passed, it is published after its package done
+	EventTestFailed                           // the test is failed, Output is the test output
+	EventFinding                              // the finding is found, see Finding
+	EventOutput                               // the raw output line of tool, e.g: go test -v output
+	EventMessage                              // the message of service, e.g: a failed test is rerun
+	EventWarning                              // the warning of service which does not fail it, Output is the message
+)
+
+var eventKindNames = map[EventKind]string{
+	EventServiceStarted:  "service started",
+	EventServiceFinished: "service finished",
+	EventPackageQueued:   "package queued",
+	EventPackageStarted:  "package started",
+	EventPackageFinished: "package finished",
+	EventTestPassed:      "test passed",
+	EventTestFailed:      "test failed",
+	EventFinding:         "finding",
+	EventOutput:          "output",
+	EventMessage:         "message",
+	EventWarning:         "warning",
+}
+
+func (k EventKind) String() string {
+	if name, ok := eventKindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Event is a progress event of service.
+type Event struct {
+	Kind    EventKind
+	Time    time.Time
+	Service string   // the service name, e.g: gtest
+	Package string   // the import path of package
+	Test    string   // the test name of test event
+	Failed  bool     // the package is failed, e.g: test failed or has problem
+	Finding *Finding // the finding of EventFinding
+	Output  string   // the line of EventOutput, the message of EventMessage and EventWarning or the output of failed test
+	Err     error    // the error of failed service
+}
+
+// EventBus streams the events of reporter and services to the subscribers.
+// a nil EventBus drops all events.
+type EventBus struct {
+	handlers []func(Event)
+	sync.Mutex
+}
+
+// NewEventBus return a event bus without subscriber.
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe add the handler which is called for each event in published order,
+// the handlers are not called concurrently, so the handler must be fast and not publish event.
+func (b *EventBus) Subscribe(handler func(Event)) {
+	b.Lock()
+	defer b.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish send the event to all handlers, the time of event is set if it is zero.
+func (b *EventBus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.Lock()
+	defer b.Unlock()
+	for _, h := range b.handlers {
+		h(e)
+	}
+}
+
+// Events return the event bus of reporter, nil if it is not set in ServiceContext.
+func (r *Reporter) Events() *EventBus {
+	return r.context.Events
+}
+
+// ServiceName return the name of service, it is the name of Node or JSONGenerate,
+// otherwise the type name.
+func ServiceName(s Service) string {
+	switch v := s.(type) {
+	case Node:
+		return v.Name()
+	case JSONGenerate:
+		return v.JName()
+	}
+	return reflect.TypeOf(s).String()
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	gocontext "context"
+	"errors"
+	"testing"
+)
+
+type EventService struct {
+	bus *EventBus
+}
+
+func (s *EventService) Name() string      { return "events" }
+func (s *EventService) Depends() []string { return nil }
+func (s *EventService) Run(ctx gocontext.Context) error {
+	s.bus.Publish(Event{Kind: EventPackageStarted, Service: "events", Package: "a"})
+	s.bus.Publish(Event{Kind: EventPackageFinished, Service: "events", Package: "a", Failed: true})
+	return errors.New("events failed")
+}
+
+func TestReporter_Events(t *testing.T) {
+	bus := NewEventBus()
+	var events []Event
+	bus.Subscribe(func(e Event) { events = append(events, e) })
+	r, _ := New(&ServiceContext{Events: bus})
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &EventService{bus: ctx.Events}, nil })
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Wait(); err == nil {
+		t.Fatal("want the error of failed service")
+	}
+	want := []EventKind{EventServiceStarted, EventPackageStarted, EventPackageFinished, EventServiceFinished}
+	if len(events) != len(want) {
+		t.Fatalf("want %d events, got %+v", len(want), events)
+	}
+	for i, e := range events {
+		if e.Kind != want[i] || e.Service != "events" || e.Time.IsZero() {
+			t.Fatalf("want %s event of events service at %d, got %+v", want[i], i, e)
+		}
+	}
+	if last := events[3]; last.Err == nil || last.Err.Error() != "events failed" {
+		t.Fatalf("want the error in finished event, got %v", last.Err)
+	}
+
+	// the nil bus drops the events.
+	var nilBus *EventBus
+	nilBus.Publish(Event{Kind: EventOutput})
+	if EventFinding.String() != "finding" || EventKind(0).String() != "unknown" {
+		t.Fatal("want the name of event kind")
+	}
+}
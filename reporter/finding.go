@@ -0,0 +1,243 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Severity is the level of finding.
+type Severity string
+
+// Severity constants
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Finding is a issue found by service, e.g: a lint problem, a file need format or a failed test.
+type Finding struct {
+	Tool     string // the tool which found it, e.g: golint
+	Rule     string // the rule or check name, e.g: the analyzer name of go vet
+	Severity Severity
+	// File is the absolute path of file, empty if the finding is not about a file,
+	// e.g: a package build failed.
+	File string
+	// the position range in file, zero if unknown.
+	Line, Col       int
+	EndLine, EndCol int
+	Message         string
+	// Fix is the suggested fix, e.g: the unified diff of gofmt, empty if no fix.
+	Fix string
+	// Snippet is the code of the finding line which the spaces are normalized,
+	// it is read from file if the service does not set it.
+	Snippet string
+	// Fingerprint identify the finding, it does not contain the line number
+	// so it is stable when the code line shifted.
+	Fingerprint string
+}
+
+// DiffHunk is a hunk of unified diff, the line is the first changed line of old file.
+type DiffHunk struct {
+	Line    int
+	Text    string
+	changed bool
+}
+
+// SplitHunks split the unified diff to hunks, return nil if it is not a diff.
+// the leading context lines are skipped, so the line of hunk is the line which need change.
+func SplitHunks(diff string) []DiffHunk {
+	var list []DiffHunk
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		if matches := regDiffHunk.FindStringSubmatch(line); matches != nil {
+			start, _ := strconv.Atoi(matches[1])
+			list = append(list, DiffHunk{Line: start, Text: line})
+			continue
+		}
+		if len(list) == 0 {
+			continue
+		}
+		h := &list[len(list)-1]
+		h.Text += "\n" + line
+		if strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+") {
+			h.changed = true
+		} else if !h.changed {
+			// skip the leading context line
+			h.Line++
+		}
+	}
+	return list
+}
+
+// Position return the position text, e.g: a.go:1:2
+func (f Finding) Position() string {
+	switch {
+	case f.Line > 0 && f.Col > 0:
+		return fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Col)
+	case f.Line > 0:
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return f.File
+}
+
+// FindingProvider a finding provide interface.
+// reporter service need implement if its result can be converted to findings,
+// the formatters, gates and baselines work on the findings.
+type FindingProvider interface {
+	// Tool return the tool name of findings.
+	Tool() string
+	Findings() []Finding
+}
+
+// Findings return the findings of all services, the findings are sorted by tool, file and position,
+// and the fingerprint is set if the service does not set it.
+// it must be called after the reporter done.
+func (r *Reporter) Findings() ([]Finding, error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return nil, ErrIsRunning
+	}
+	_, list := r.findings()
+	return list, nil
+}
+
+// findings return the tool names of FindingProvider service and the findings of them.
+func (r *Reporter) findings() ([]string, []Finding) {
+	var (
+		tools []string
+		list  []Finding
+	)
+	for _, s := range r.services[false] {
+		fp, ok := s.(FindingProvider)
+		if !ok {
+			continue
+		}
+		tools = append(tools, fp.Tool())
+		list = append(list, fp.Findings()...)
+	}
+	sortFindings(list)
+
+	root := r.rootDir()
+	codes := snippets{}
+	// the same finding may occur more than once in a file,
+	// add the occurrence number to keep the fingerprint unique.
+	seen := map[string]int{}
+	for i := range list {
+		f := &list[i]
+		if f.Snippet == "" {
+			f.Snippet = codes.line(f.File, f.Line)
+		}
+		if f.Fingerprint == "" {
+			f.Fingerprint = Fingerprint(*f, root)
+		}
+		n := seen[f.Fingerprint]
+		seen[f.Fingerprint] = n + 1
+		if n > 0 {
+			f.Fingerprint = fmt.Sprintf("%s-%d", f.Fingerprint, n)
+		}
+	}
+	return tools, list
+}
+
+// rootDir return the root dir of project, it is the config file dir or working dir.
+func (r *Reporter) rootDir() string {
+	if r.context != nil && r.context.Config != nil {
+		return r.context.Config.Dir()
+	}
+	dir, _ := os.Getwd()
+	return dir
+}
+
+func sortFindings(list []Finding) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Tool != b.Tool {
+			return a.Tool < b.Tool
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Col != b.Col {
+			return a.Col < b.Col
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Message < b.Message
+	})
+}
+
+// snippets cache the code lines of files which are read for finding snippet.
+type snippets map[string][]string
+
+// line return the code of the line in file and the spaces are normalized,
+// so the indent change does not change the snippet. return empty if the line not found.
+func (s snippets) line(file string, line int) string {
+	if file == "" || line < 1 {
+		return ""
+	}
+	lines, ok := s[file]
+	if !ok {
+		if data, err := ioutil.ReadFile(file); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		s[file] = lines
+	}
+	if line > len(lines) {
+		return ""
+	}
+	return NormalizeSnippet(lines[line-1])
+}
+
+// NormalizeSnippet replace the consecutive spaces of code with one space and trim the spaces.
+func NormalizeSnippet(code string) string {
+	return strings.Join(strings.Fields(code), " ")
+}
+
+// regMessagePos match the position in message, e.g: a_test.go:12:3
+var regMessagePos = regexp.MustCompile(`\.go:\d+(:\d+)?`)
+
+// Fingerprint return the hash of tool, rule, file, code snippet and message of finding.
+// the file is relative to root dir, and the line and column are not contained,
+// so it is stable when the project moved or the code line shifted.
+func Fingerprint(f Finding, root string) string {
+	file := f.File
+	if root != "" && filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(root, file); err == nil {
+			file = rel
+		}
+	}
+	h := sha1.New()
+	for _, s := range []string{f.Tool, f.Rule, filepath.ToSlash(file), f.Snippet, regMessagePos.ReplaceAllString(f.Message, ".go")} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	gocontext "context"
+	"testing"
+)
+
+type FindingService struct {
+	HelloService
+}
+
+func (s *FindingService) Tool() string {
+	return "golint"
+}
+
+func (s *FindingService) Findings() []Finding {
+	return []Finding{
+		{Tool: "golint", Rule: "golint", File: "/p/b.go", Line: 20, Message: "exported func A should have comment"},
+		{Tool: "golint", Rule: "golint", File: "/p/a.go", Line: 9, Message: "exported func A should have comment"},
+		{Tool: "golint", Rule: "golint", File: "/p/a.go", Line: 3, Message: "exported func A should have comment"},
+	}
+}
+
+func TestReporter_Findings(t *testing.T) {
+	r, err := New(&ServiceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &FindingService{}, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &HelloService{}, nil })
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+
+	list, err := r.Findings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("want 3 findings, got %d", len(list))
+	}
+	if list[0].Position() != "/p/a.go:3" || list[2].File != "/p/b.go" {
+		t.Fatalf("want findings sorted by file and line, got %s,%s,%s", list[0].Position(), list[1].Position(), list[2].Position())
+	}
+	seen := map[string]bool{}
+	for _, f := range list {
+		if f.Fingerprint == "" || seen[f.Fingerprint] {
+			t.Fatalf("want unique fingerprint, got %q", f.Fingerprint)
+		}
+		seen[f.Fingerprint] = true
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	f := Finding{Tool: "gotest", Rule: "TestA", File: "/a/p/a_test.go", Line: 12, Message: "a_test.go:12: want 1"}
+	moved := f
+	moved.File, moved.Line, moved.Message = "/b/p/a_test.go", 20, "a_test.go:20: want 1"
+	if Fingerprint(f, "/a") != Fingerprint(moved, "/b") {
+		t.Fatal("want same fingerprint if the line shifted or the project moved")
+	}
+	other := f
+	other.Message = "a_test.go:12: want 2"
+	if Fingerprint(f, "/a") == Fingerprint(other, "/a") {
+		t.Fatal("want different fingerprint for different message")
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package formater
+
+import "encoding/xml"
+
+// CheckstyleVersion is the checkstyle version of the output.
+const CheckstyleVersion = "4.3"
+
+// Checkstyle is the root of checkstyle xml report,
+// which is read by Jenkins Warnings NG and many code review bots.
+//
+//	<checkstyle version="4.3">
+//	  <file name="a.go">
+//	    <error line="1" column="2" severity="warning" source="golint" message="..."/>
+//	  </file>
+//	</checkstyle>
+type Checkstyle struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []CheckstyleFile `xml:"file"`
+}
+
+// CheckstyleFile is a checked file and its problems.
+type CheckstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []CheckstyleError `xml:"error"`
+}
+
+// CheckstyleError is a problem of file.
+type CheckstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Source   string `xml:"source,attr"`
+	Message  string `xml:"message,attr"`
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package formater
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// HTML level class name, the html report page will render different color by it.
+const (
+	HTMLLevelPass = "pass"
+	HTMLLevelFail = "fail"
+	HTMLLevelWarn = "warn"
+	HTMLLevelSkip = "skip"
+	HTMLLevelInfo = "info"
+)
+
+var htmlSnippets = template.Must(template.New("snippets").Funcs(template.FuncMap{
+	"diffClass": diffLineClass,
+}).Parse(`
+{{define "stat"}}<div class="stat {{.Level}}"><span class="num">{{.Value}}</span><span class="label">{{.Label}}</span></div>{{end}}
+
+{{define "coverage"}}{{if lt .Pct 0.0}}<span class="cover none">no coverage</span>{{else}}<span class="cover" title="{{printf "%.2f" .Pct}}% of statements"><span class="bar"><span class="fill {{.Level}}" style="width:{{printf "%.2f" .Width}}%"></span></span><span class="pct">{{printf "%.1f" .Pct}}%</span></span>{{end}}{{end}}
+
+{{define "diff"}}<details class="group {{.Level}}"{{if .Open}} open{{end}}><summary>{{.Title}}</summary><pre class="diff"><code>{{range .Lines}}<span class="line {{diffClass .}}">{{.}}</span>
+{{end}}</code></pre></details>{{end}}
+
+{{define "details"}}<details class="group {{.Level}}"{{if .Open}} open{{end}}><summary>{{.Title}}</summary>{{.Body}}</details>{{end}}
+
+{{define "table"}}<table class="list"><thead><tr>{{range .Head}}<th>{{.}}</th>{{end}}</tr></thead><tbody>{{range .Rows}}<tr class="{{.Level}}">{{range .Cells}}<td>{{.}}</td>{{end}}</tr>{{end}}</tbody></table>{{end}}
+`))
+
+func execSnippet(name string, data interface{}) string {
+	var buf bytes.Buffer
+	if err := htmlSnippets.ExecuteTemplate(&buf, name, data); err != nil {
+		return template.HTMLEscapeString(err.Error())
+	}
+	return buf.String()
+}
+
+// HTMLStat render a dashboard number card, such as "12 tests".
+func HTMLStat(label string, value interface{}, level string) string {
+	return execSnippet("stat", struct {
+		Label, Level string
+		Value        interface{}
+	}{label, level, value})
+}
+
+// HTMLCoverage render a coverage bar for the percentage.
+// show "no coverage" if the pct less than zero.
+func HTMLCoverage(pct float32) string {
+	level := HTMLLevelPass
+	switch {
+	case pct < 40:
+		level = HTMLLevelFail
+	case pct < 70:
+		level = HTMLLevelWarn
+	}
+	width := pct
+	if width > 100 {
+		width = 100
+	}
+	return execSnippet("coverage", struct {
+		Pct, Width float32
+		Level      string
+	}{pct, width, level})
+}
+
+// HTMLDiff render a collapsible unified diff block.
+// the go code in diff will be highlighted by report page script.
+func HTMLDiff(title, diff, level string, open bool) string {
+	return execSnippet("diff", struct {
+		Title, Level string
+		Open         bool
+		Lines        []string
+	}{title, level, open, strings.Split(strings.TrimRight(diff, "\n"), "\n")})
+}
+
+// HTMLDetails render a collapsible block,the body must be a safe html content.
+func HTMLDetails(title, body, level string, open bool) string {
+	return execSnippet("details", struct {
+		Title, Level string
+		Open         bool
+		Body         template.HTML
+	}{title, level, open, template.HTML(body)})
+}
+
+// HTMLRow is a table row, the cell can be a template.HTML if it is safe html content.
+type HTMLRow struct {
+	Level string
+	Cells []interface{}
+}
+
+// HTMLTable render a list table.
+func HTMLTable(head []string, rows []HTMLRow) string {
+	return execSnippet("table", struct {
+		Head []string
+		Rows []HTMLRow
+	}{head, rows})
+}
+
+// HTMLPre render text as preformatted block.
+func HTMLPre(text string) string {
+	return fmt.Sprintf(`<pre class="output">%s</pre>`, template.HTMLEscapeString(text))
+}
+
+func diffLineClass(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return "head"
+	case strings.HasPrefix(line, "@@"):
+		return "hunk"
+	case strings.HasPrefix(line, "+"):
+		return "add"
+	case strings.HasPrefix(line, "-"):
+		return "del"
+	}
+	return "ctx"
+}
@@ -51,6 +51,10 @@ type JUnitTestCase struct {
 	Time        float32           `xml:"time,attr"`
 	SkipMessage *JUnitSkipMessage `xml:"skipped,omitempty"`
 	Failure     *JUnitFailure     `xml:"failure,omitempty"`
+	// FlakyFailures the failed runs of the test which passed on rerun.
+	FlakyFailures []JUnitRerunFailure `xml:"flakyFailure,omitempty"`
+	// RerunFailures the failed reruns of the test which is still failed.
+	RerunFailures []JUnitRerunFailure `xml:"rerunFailure,omitempty"`
 }
 
 // JUnitSkipMessage contains the reason why a testcase was skipped.
@@ -64,6 +68,14 @@ type JUnitProperty struct {
 	Value string `xml:"value,attr"`
 }
 
+// JUnitRerunFailure a failed run of the rerun test, it is the extension of maven surefire, see
+// https://maven.apache.org/surefire/maven-surefire-plugin/xsd/surefire-test-report-3.0.xsd
+type JUnitRerunFailure struct {
+	Message    string `xml:"message,attr"`
+	Type       string `xml:"type,attr"`
+	StackTrace string `xml:"stackTrace,omitempty"`
+}
+
 // JUnitFailure contains data related to a failed test.
 type JUnitFailure struct {
 	Message  string `xml:"message,attr"`
@@ -0,0 +1,225 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ysqi/gcodesharp/config"
+)
+
+// The metric names which can be checked by quality gate.
+const (
+	MetricFailedTests      = "failed_tests"
+	MetricFailedPackages   = "failed_packages"
+	MetricCoverage         = "coverage"
+	MetricTotalCoverage    = "total_coverage"
+	MetricUnformattedFiles = "unformatted_files"
+	MetricLintProblems     = "lint_problems"
+	MetricVetProblems      = "vet_problems"
+)
+
+// The metric names which are recorded to history, they are not checked by quality gate.
+const (
+	MetricTests            = "tests"           // the number of tests of each package
+	MetricSkippedTests     = "skipped_tests"   // the number of skipped tests
+	MetricFlakyTests       = "flaky_tests"     // the number of tests passed on rerun
+	MetricDuration         = "duration"        // the seconds to run tool or test package
+	MetricBenchNsPerOp     = "bench_ns_per_op" // the mean ns/op of each benchmark
+	MetricBenchBytesPerOp  = "bench_bytes_per_op"
+	MetricBenchAllocsPerOp = "bench_allocs_per_op"
+)
+
+// Metric is a measured value of service result, e.g: the lint problem count.
+type Metric struct {
+	Name string `json:"name"`
+	// Target is the measured object, e.g: the package import path of coverage.
+	// empty means the whole result of service.
+	Target string  `json:"target,omitempty"`
+	Value  float64 `json:"value"`
+}
+
+// MetricProvider a metric provide interface.
+// reporter service need implement if its result can be checked by quality gate.
+type MetricProvider interface {
+	Metrics() []Metric
+}
+
+// Gate is a quality gate rule of metric,
+// each value of the metric must be in the range [Min,Max], the nil bound is not checked.
+type Gate struct {
+	Metric string
+	Min    *float64
+	Max    *float64
+}
+
+func (g Gate) String() string {
+	switch {
+	case g.Min != nil && g.Max != nil:
+		return fmt.Sprintf("%g <= %s <= %g", *g.Min, g.Metric, *g.Max)
+	case g.Min != nil:
+		return fmt.Sprintf("%s >= %g", g.Metric, *g.Min)
+	case g.Max != nil:
+		return fmt.Sprintf("%s <= %g", g.Metric, *g.Max)
+	}
+	return g.Metric
+}
+
+func (g Gate) check(v float64) bool {
+	if g.Min != nil && v < *g.Min {
+		return false
+	}
+	if g.Max != nil && v > *g.Max {
+		return false
+	}
+	return true
+}
+
+// GateResult is the check result of a gate for one metric value.
+type GateResult struct {
+	Gate   Gate
+	Target string
+	Value  float64
+	// Measured is false if no service provide the metric, the gate is skipped.
+	Measured bool
+	Passed   bool
+}
+
+// DefaultGates return the gates which are checked if no gate is set in config,
+// no test and package can be failed.
+func DefaultGates() []Gate {
+	zero := float64(0)
+	return []Gate{
+		{Metric: MetricFailedTests, Max: &zero},
+		{Metric: MetricFailedPackages, Max: &zero},
+	}
+}
+
+// GatesFromConfig convert the gates of config to gate rules,
+// return the DefaultGates if no gate is set.
+func GatesFromConfig(c config.Gates) []Gate {
+	var gates []Gate
+	add := func(metric string, min, max *float64) {
+		if min != nil || max != nil {
+			gates = append(gates, Gate{Metric: metric, Min: min, Max: max})
+		}
+	}
+	add(MetricFailedTests, nil, c.MaxFailedTests)
+	add(MetricFailedPackages, nil, c.MaxFailedPackages)
+	add(MetricCoverage, c.MinCoverage, nil)
+	add(MetricTotalCoverage, c.MinTotalCoverage, nil)
+	add(MetricUnformattedFiles, nil, c.MaxUnformattedFiles)
+	add(MetricLintProblems, nil, c.MaxLintProblems)
+	add(MetricVetProblems, nil, c.MaxVetProblems)
+	if len(gates) == 0 {
+		return DefaultGates()
+	}
+	return gates
+}
+
+// CheckGates check the metrics of all services with the gates.
+// it must be called after the reporter done.
+func (r *Reporter) CheckGates(gates []Gate) ([]GateResult, error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return nil, ErrIsRunning
+	}
+	metrics := map[string][]Metric{}
+	for _, m := range r.metrics() {
+		metrics[m.Name] = append(metrics[m.Name], m)
+	}
+	var results []GateResult
+	for _, g := range gates {
+		list := metrics[g.Metric]
+		if len(list) == 0 {
+			results = append(results, GateResult{Gate: g, Passed: true})
+			continue
+		}
+		for _, m := range list {
+			results = append(results, GateResult{
+				Gate:     g,
+				Target:   m.Target,
+				Value:    m.Value,
+				Measured: true,
+				Passed:   g.check(m.Value),
+			})
+		}
+	}
+	return results, nil
+}
+
+// Metrics return the metrics of all services.
+// it must be called after the reporter done.
+func (r *Reporter) Metrics() ([]Metric, error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return nil, ErrIsRunning
+	}
+	return r.metrics(), nil
+}
+
+func (r *Reporter) metrics() []Metric {
+	var list []Metric
+	for _, s := range r.services[false] {
+		mp, ok := s.(MetricProvider)
+		if !ok {
+			continue
+		}
+		list = append(list, mp.Metrics()...)
+	}
+	return list
+}
+
+// GatesPassed report whether all gates passed.
+func GatesPassed(results []GateResult) bool {
+	for _, r := range results {
+		if !r.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteGateSummary write the gate check results as a text table.
+func WriteGateSummary(w io.Writer, results []GateResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESULT\tGATE\tVALUE\tTARGET")
+	failed := 0
+	for _, r := range results {
+		status, value := "PASS", fmt.Sprintf("%g", r.Value)
+		switch {
+		case !r.Measured:
+			status, value = "SKIP", "-"
+		case !r.Passed:
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", status, r.Gate, value, r.Target)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		_, err := fmt.Fprintf(w, "quality gate failed: %d of %d checks failed\n", failed, len(results))
+		return err
+	}
+	_, err := fmt.Fprintf(w, "quality gate passed: %d checks\n", len(results))
+	return err
+}
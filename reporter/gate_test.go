@@ -0,0 +1,93 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"bytes"
+	gocontext "context"
+	"strings"
+	"testing"
+
+	"github.com/ysqi/gcodesharp/config"
+)
+
+type MetricService struct {
+	HelloService
+}
+
+func (m *MetricService) Metrics() []Metric {
+	return []Metric{
+		{Name: MetricLintProblems, Value: 3},
+		{Name: MetricCoverage, Target: "a", Value: 80},
+		{Name: MetricCoverage, Target: "b", Value: 45.5},
+	}
+}
+
+func TestReporter_CheckGates(t *testing.T) {
+	r, err := New(&ServiceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &MetricService{}, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &HelloService{}, nil })
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+
+	max, min, zero := 10.0, 60.0, 0.0
+	gates := GatesFromConfig(config.Gates{
+		MaxLintProblems: &max,
+		MinCoverage:     &min,
+		MaxVetProblems:  &zero,
+	})
+	if len(gates) != 3 {
+		t.Fatalf("want 3 gates, got %d", len(gates))
+	}
+	// no test and package can be failed if no gate is set.
+	if def := GatesFromConfig(config.Gates{}); len(def) != 2 || def[0].Metric != MetricFailedTests || *def[1].Max != 0 {
+		t.Fatalf("want the default gates of failed tests and packages, got %v", def)
+	}
+	results, err := r.CheckGates(gates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("want 4 gate results, got %d", len(results))
+	}
+	if GatesPassed(results) {
+		t.Fatal("want gates failed because the coverage of b less than 60")
+	}
+	for _, res := range results {
+		want := res.Target != "b"
+		if res.Passed != want {
+			t.Fatalf("want gate %s of %q passed=%v, got %v", res.Gate, res.Target, want, res.Passed)
+		}
+		if res.Gate.Metric == MetricVetProblems && res.Measured {
+			t.Fatal("want vet gate skipped because no service provide the metric")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteGateSummary(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"FAIL", "coverage >= 60", "SKIP", "quality gate failed: 1 of 4"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("want summary contains %q, got:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"runtime"
+	"time"
+)
+
+type htmlSection struct {
+	ID      string
+	Title   string
+	Summary template.HTML
+	Groups  []template.HTML
+}
+
+type htmlPage struct {
+	Created  string
+	Env      string
+	Sections []htmlSection
+}
+
+// OutputHTML write a single self-contained html review report to writer.
+// the css and script are inline, so the report can be opened without network.
+func (r *Reporter) OutputHTML(writer io.Writer) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return ErrIsRunning
+	}
+
+	page := htmlPage{
+		Created: time.Now().Format("2006-01-02 15:04:05"),
+		Env:     fmt.Sprintf("%s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH),
+	}
+	for _, s := range r.services[false] {
+		hs, ok := s.(HTMLGennerate)
+		if !ok {
+			continue
+		}
+		section := htmlSection{
+			ID:      fmt.Sprintf("service-%d", len(page.Sections)),
+			Title:   hs.HTitle(),
+			Summary: template.HTML(hs.HSummary()),
+		}
+		for _, g := range hs.HGroupDetail() {
+			section.Groups = append(section.Groups, template.HTML(g))
+		}
+		page.Sections = append(page.Sections, section)
+	}
+	return htmlTpl.Execute(writer, page)
+}
+
+var htmlTpl = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>GCodeSharp Review Report</title>
+<style>
+body{margin:0;font:14px/1.5 -apple-system,"Segoe UI",Helvetica,Arial,sans-serif;color:#24292e;background:#f6f8fa}
+header{background:#24292e;color:#fff;padding:16px 32px}
+header h1{margin:0;font-size:20px}
+header .meta{color:#aab;font-size:12px}
+nav{padding:8px 32px;background:#fff;border-bottom:1px solid #e1e4e8}
+nav a{margin-right:16px;color:#0366d6;text-decoration:none}
+main{padding:16px 32px}
+section{background:#fff;border:1px solid #e1e4e8;border-radius:6px;margin-bottom:24px;padding:16px}
+section h2{margin-top:0;font-size:18px}
+.dashboard{display:flex;flex-wrap:wrap;gap:16px}
+.card{flex:1 1 260px;background:#fff;border:1px solid #e1e4e8;border-radius:6px;padding:12px 16px}
+.card h3{margin:0 0 8px;font-size:15px}
+.card h3 a{color:inherit;text-decoration:none}
+.summary{display:flex;flex-wrap:wrap;gap:8px;margin-bottom:12px}
+.stat{border-radius:4px;padding:4px 10px;background:#f1f8ff;min-width:64px;text-align:center}
+.stat .num{display:block;font-size:20px;font-weight:600}
+.stat .label{font-size:12px;color:#586069}
+.pass{color:#22863a}.stat.pass{background:#e6ffed}
+.fail{color:#cb2431}.stat.fail{background:#ffeef0}
+.warn{color:#b08800}.stat.warn{background:#fffbdd}
+.skip{color:#6a737d}.stat.skip{background:#f6f8fa}
+details.group{border:1px solid #e1e4e8;border-radius:4px;margin:6px 0}
+details.group>summary{cursor:pointer;padding:6px 10px;background:#fafbfc;font-family:monospace}
+details.group.fail>summary{border-left:4px solid #cb2431}
+details.group.warn>summary{border-left:4px solid #dbab09}
+details.group.pass>summary{border-left:4px solid #28a745}
+details.group.skip>summary{border-left:4px solid #959da5}
+details.group>*:not(summary){margin:8px 10px}
+pre{overflow:auto;margin:0;padding:8px;background:#fafbfc;font:12px/1.45 SFMono-Regular,Consolas,Menlo,monospace;color:#24292e}
+pre.diff .line{display:block;white-space:pre}
+pre.diff .add{background:#e6ffed}
+pre.diff .del{background:#ffeef0}
+pre.diff .hunk{color:#6f42c1;background:#f1f8ff}
+pre.diff .head{color:#6a737d;font-weight:600}
+.tok-kw{color:#d73a49}.tok-str{color:#032f62}.tok-com{color:#6a737d;font-style:italic}.tok-num{color:#005cc5}
+table.list{border-collapse:collapse;width:100%}
+table.list th,table.list td{border-bottom:1px solid #eaecef;padding:4px 8px;text-align:left;vertical-align:top}
+table.list th{background:#fafbfc;font-weight:600}
+.cover{display:inline-flex;align-items:center;gap:6px}
+.cover .bar{display:inline-block;width:120px;height:8px;background:#eaecef;border-radius:4px;overflow:hidden}
+.cover .fill{display:block;height:100%}
+.cover .fill.pass{background:#28a745}.cover .fill.warn{background:#dbab09}.cover .fill.fail{background:#cb2431}
+.cover.none{color:#6a737d;font-size:12px}
+.toolbar{margin-bottom:8px}
+.toolbar button{font-size:12px;margin-right:6px}
+.empty{color:#6a737d}
+</style>
+</head>
+<body>
+<header>
+<h1>GCodeSharp Review Report</h1>
+<div class="meta">created at {{.Created}} &middot; {{.Env}}</div>
+</header>
+<nav>{{range .Sections}}<a href="#{{.ID}}">{{.Title}}</a>{{end}}</nav>
+<main>
+<div class="dashboard">
+{{range .Sections}}<div class="card"><h3><a href="#{{.ID}}">{{.Title}}</a></h3><div class="summary">{{.Summary}}</div></div>
+{{else}}<p class="empty">no service provide html report.</p>{{end}}
+</div>
+{{range .Sections}}
+<section id="{{.ID}}">
+<h2>{{.Title}}</h2>
+<div class="summary">{{.Summary}}</div>
+{{if .Groups}}<div class="toolbar"><button data-toggle="open">expand all</button><button data-toggle="close">collapse all</button></div>
+{{range .Groups}}{{.}}
+{{end}}{{else}}<p class="empty">nothing to review.</p>{{end}}
+</section>
+{{end}}
+</main>
+<script>
+(function(){
+  var kw=/^(break|case|chan|const|continue|default|defer|else|fallthrough|for|func|go|goto|if|import|interface|map|package|range|return|select|struct|switch|type|var|nil|true|false|iota)$/;
+  var tok=/(\/\/.*$|\/\*.*?\*\/)|("(?:[^"\\]|\\.)*"|\x60[^\x60]*\x60|'(?:[^'\\]|\\.)*')|(\b\d+(?:\.\d+)?\b)|([A-Za-z_]\w*)/g;
+  function span(cls,text){var s=document.createElement("span");s.className=cls;s.textContent=text;return s}
+  function highlight(el){
+    var text=el.textContent,prefix="";
+    if(/^[+\- ]/.test(text)){prefix=text.charAt(0);text=text.substr(1)}
+    var frag=document.createDocumentFragment(),last=0,m;
+    frag.appendChild(document.createTextNode(prefix));
+    tok.lastIndex=0;
+    while((m=tok.exec(text))!==null){
+      var cls=m[1]?"tok-com":m[2]?"tok-str":m[3]?"tok-num":(m[4]&&kw.test(m[4]))?"tok-kw":"";
+      if(!cls){continue}
+      frag.appendChild(document.createTextNode(text.slice(last,m.index)));
+      frag.appendChild(span(cls,m[0]));
+      last=m.index+m[0].length;
+    }
+    frag.appendChild(document.createTextNode(text.slice(last)));
+    el.textContent="";
+    el.appendChild(frag);
+  }
+  var lines=document.querySelectorAll("pre.diff .add,pre.diff .del,pre.diff .ctx");
+  for(var i=0;i<lines.length;i++){highlight(lines[i])}
+  var buttons=document.querySelectorAll(".toolbar button");
+  for(var j=0;j<buttons.length;j++){
+    buttons[j].addEventListener("click",function(e){
+      var open=e.target.getAttribute("data-toggle")==="open";
+      var groups=e.target.closest("section").querySelectorAll("details.group");
+      for(var k=0;k<groups.length;k++){groups[k].open=open}
+    });
+  }
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,69 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"bytes"
+	gocontext "context"
+	"strings"
+	"testing"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+type HTMLService struct {
+	HelloService
+}
+
+func (h *HTMLService) HTitle() string {
+	return "hello"
+}
+func (h *HTMLService) HSummary() string {
+	return formater.HTMLStat("files", 2, formater.HTMLLevelWarn)
+}
+func (h *HTMLService) HGroupDetail() []string {
+	return []string{formater.HTMLDiff("a.go", "@@ -1 +1 @@\n-var a=<b>\n+var a = <b>\n", formater.HTMLLevelWarn, false)}
+}
+
+func TestReporter_OutputHTML(t *testing.T) {
+	r, err := New(&ServiceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &HTMLService{}, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &HelloService{}, nil })
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+
+	var buf bytes.Buffer
+	if err := r.OutputHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{"<h2>hello</h2>", `<span class="num">2</span>`, `<span class="line del">-var a=&lt;b&gt;</span>`} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("want html contains %q", want)
+		}
+	}
+	if strings.Contains(html, "<b>") {
+		t.Fatal("want the diff content escaped")
+	}
+	if strings.Contains(html, "src=\"http") || strings.Contains(html, "href=\"http") {
+		t.Fatal("want a self-contained html without network resource")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Jobs runs the job of each package of a service on the scheduler,
+// publishes the progress events of packages and records the first error of service.
+// the job puts its result to the slot of its package, so no lock is needed,
+// and the service merges the results in a stable order after Wait.
+type Jobs struct {
+	ctx     gocontext.Context
+	cancel  gocontext.CancelFunc
+	service string
+	events  *EventBus
+	group   *Group
+	errh    func(format string, args ...interface{})
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewJobs return the jobs of service, e.g: gtest. errh is called with the error of service.
+// the jobs are canceled if ctx is done or a error is reported.
+func NewJobs(ctx gocontext.Context, service string, scheduler *Scheduler, events *EventBus,
+	errh func(format string, args ...interface{})) *Jobs {
+	ctx, cancel := gocontext.WithCancel(ctx)
+	return &Jobs{
+		ctx:     ctx,
+		cancel:  cancel,
+		service: service,
+		events:  events,
+		group:   scheduler.Group(ctx, service),
+		errh:    errh,
+	}
+}
+
+// Context return the context of jobs, the tool process of job is killed if it is done.
+func (j *Jobs) Context() gocontext.Context {
+	return j.ctx
+}
+
+// Go submit the job of package, the job return whether the package is failed and its findings,
+// which are published when the job finished. the job is skipped if it is canceled before started.
+func (j *Jobs) Go(pkg string, job func() (failed bool, findings []Finding)) {
+	j.Publish(Event{Kind: EventPackageQueued, Package: pkg})
+	j.group.Go(func() {
+		j.Publish(Event{Kind: EventPackageStarted, Package: pkg})
+		failed, findings := job()
+		j.done(pkg, failed, findings)
+	})
+}
+
+// Skip publish the package which is done without job, e.g: the package can not be loaded.
+func (j *Jobs) Skip(pkg string, failed bool, findings []Finding) {
+	j.Publish(Event{Kind: EventPackageQueued, Package: pkg})
+	j.done(pkg, failed, findings)
+}
+
+// done publish the findings and the event of package which is finished.
+func (j *Jobs) done(pkg string, failed bool, findings []Finding) {
+	for i := range findings {
+		j.Publish(Event{Kind: EventFinding, Package: pkg, Finding: &findings[i]})
+	}
+	j.Publish(Event{Kind: EventPackageFinished, Package: pkg, Failed: failed})
+}
+
+// Publish send the event of service, e.g: the result of test.
+func (j *Jobs) Publish(e Event) {
+	e.Service = j.service
+	j.events.Publish(e)
+}
+
+// Output return the func which publish each line of tool output, nil if the events are dropped.
+func (j *Jobs) Output(pkg string) func(line string) {
+	if j.events == nil {
+		return nil
+	}
+	return func(line string) {
+		j.Publish(Event{Kind: EventOutput, Package: pkg, Output: line})
+	}
+}
+
+// Log publish the message of package, e.g: a failed test is rerun.
+func (j *Jobs) Log(pkg, format string, args ...interface{}) {
+	j.Publish(Event{Kind: EventMessage, Package: pkg, Output: fmt.Sprintf(format, args...)})
+}
+
+// Warn publish the warning of package which does not fail the service,
+// e.g: the cover profile can not be loaded.
+func (j *Jobs) Warn(pkg, format string, args ...interface{}) {
+	j.Publish(Event{Kind: EventWarning, Package: pkg, Output: fmt.Sprintf(format, args...)})
+}
+
+// Error record the first error of service, report it and cancel the running jobs.
+func (j *Jobs) Error(msg string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.err == nil {
+		j.err = errors.New(msg)
+	}
+	j.errh("%s: %s", j.service, msg)
+	j.cancel()
+}
+
+// Wait blocks until all jobs are done or skipped, return the first error of service.
+func (j *Jobs) Wait() error {
+	j.group.Wait()
+	j.cancel()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	gocontext "context"
+	"reflect"
+	"testing"
+)
+
+func TestJobs(t *testing.T) {
+	bus := NewEventBus()
+	kinds := map[EventKind]int{}
+	bus.Subscribe(func(e Event) {
+		if e.Service != "gvet" {
+			t.Errorf("want the service of event set, got %q", e.Service)
+		}
+		kinds[e.Kind]++
+	})
+	var reported string
+	jobs := NewJobs(gocontext.Background(), "gvet", NewScheduler(1, nil), bus, func(format string, args ...interface{}) {
+		reported = args[0].(string) + ":" + args[1].(string)
+	})
+	jobs.Skip("p/a", true, nil)
+	jobs.Log("p/a", "rerun %s", "TestA")
+	jobs.Warn("p/a", "no cover profile")
+	jobs.Go("p/b", func() (bool, []Finding) {
+		jobs.Error("vet crashed")
+		return true, []Finding{{Tool: "govet"}}
+	})
+	jobs.Go("p/c", func() (bool, []Finding) {
+		t.Error("want the job skipped after error")
+		return false, nil
+	})
+	err := jobs.Wait()
+	if err == nil || err.Error() != "vet crashed" || reported != "gvet:vet crashed" {
+		t.Fatalf("want the first error reported, got %v and %q", err, reported)
+	}
+	if jobs.Context().Err() == nil {
+		t.Fatal("want the context canceled after error")
+	}
+	// p/c is queued but not started.
+	want := map[EventKind]int{EventPackageQueued: 3, EventPackageStarted: 1, EventFinding: 1, EventPackageFinished: 2,
+		EventMessage: 1, EventWarning: 1}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("want events %v, got %v", want, kinds)
+	}
+}
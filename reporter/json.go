@@ -0,0 +1,134 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// JSONVersion is the version of json report schema,
+// it is increased when the schema is changed incompatibly.
+const JSONVersion = 1
+
+// JSONGenerate a json generate interface.
+// reporter service need implement if its report can be exported to json.
+type JSONGenerate interface {
+	// JName return the name of service report, e.g: gtest.
+	JName() string
+	// JReport return the report of service which is encoded to json,
+	// it can be decoded back by JSONService.Decode.
+	JReport() interface{}
+	// JError return the system error of service, nil if no error.
+	JError() error
+}
+
+// JSONReport is the json report of all services.
+type JSONReport struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Cost    float32   `json:"cost"` // the seconds of all services run
+	Env     struct {
+		GoVersion string `json:"go_version"`
+		OS        string `json:"os"`
+		Arch      string `json:"arch"`
+	} `json:"env"`
+	Services []*JSONService `json:"services"`
+}
+
+// JSONService is the json report of a service.
+type JSONService struct {
+	Name  string `json:"name"`
+	Error string `json:"error,omitempty"`
+	// Report is the report json of service, decode it by Decode method.
+	Report json.RawMessage `json:"report"`
+}
+
+// Decode decode the report of service into v, v is the json schema of service, e.g: *gtest.JSONReport.
+func (s *JSONService) Decode(v interface{}) error {
+	if err := json.Unmarshal(s.Report, v); err != nil {
+		return fmt.Errorf("decode %s report:%s", s.Name, err)
+	}
+	return nil
+}
+
+// Service return the report of service by name, nil if not found.
+func (r *JSONReport) Service(name string) *JSONService {
+	for _, s := range r.Services {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// ReadJSON read the json report which is saved by OutputJSON.
+func ReadJSON(r io.Reader) (*JSONReport, error) {
+	report := &JSONReport{}
+	if err := json.NewDecoder(r).Decode(report); err != nil {
+		return nil, fmt.Errorf("decode json report:%s", err)
+	}
+	if report.Version == 0 {
+		return nil, errors.New("not a gcodesharp json report")
+	}
+	if report.Version > JSONVersion {
+		return nil, fmt.Errorf("json report version %d is not supported, upgrade gcodesharp", report.Version)
+	}
+	return report, nil
+}
+
+// OutputJSON write the json report of all services to writer.
+func (r *Reporter) OutputJSON(w io.Writer) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return ErrIsRunning
+	}
+	report := &JSONReport{
+		Version:  JSONVersion,
+		Created:  r.created,
+		Cost:     r.cost,
+		Services: []*JSONService{},
+	}
+	report.Env.GoVersion = runtime.Version()
+	report.Env.OS = runtime.GOOS
+	report.Env.Arch = runtime.GOARCH
+	for _, s := range r.services[false] {
+		js, ok := s.(JSONGenerate)
+		if !ok {
+			continue
+		}
+		data, err := json.Marshal(js.JReport())
+		if err != nil {
+			return fmt.Errorf("encode %s report:%s", js.JName(), err)
+		}
+		service := &JSONService{
+			Name:   js.JName(),
+			Report: data,
+		}
+		if err := js.JError(); err != nil {
+			service.Error = err.Error()
+		}
+		report.Services = append(report.Services, service)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"bytes"
+	gocontext "context"
+	"errors"
+	"testing"
+)
+
+type GofmtService struct {
+	HelloService
+}
+
+type jsonResult struct {
+	Files []string `json:"files"`
+}
+
+func (s *GofmtService) JName() string {
+	return "gfmt"
+}
+
+func (s *GofmtService) JReport() interface{} {
+	return &jsonResult{Files: []string{"a.go"}}
+}
+
+func (s *GofmtService) JError() error {
+	return errors.New("gofmt crashed")
+}
+
+func TestReporter_OutputJSON(t *testing.T) {
+	r, err := New(&ServiceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &GofmtService{}, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &HelloService{}, nil })
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+
+	var buf bytes.Buffer
+	if err := r.OutputJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	report, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Version != JSONVersion || report.Env.GoVersion == "" || report.Created.IsZero() {
+		t.Fatalf("want version, env and created time, got %+v", report)
+	}
+	if len(report.Services) != 1 || report.Service("gtest") != nil {
+		t.Fatalf("want only the gfmt report, got %d reports", len(report.Services))
+	}
+	s := report.Service("gfmt")
+	if s == nil || s.Error != "gofmt crashed" {
+		t.Fatalf("want the gfmt report with error, got %+v", s)
+	}
+	var result jsonResult
+	if err := s.Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 1 || result.Files[0] != "a.go" {
+		t.Fatalf("want the gfmt report decoded, got %+v", result)
+	}
+
+	if _, err := ReadJSON(bytes.NewBufferString(`{"version":99}`)); err == nil {
+		t.Fatal("want error for unsupported version")
+	}
+	if _, err := ReadJSON(bytes.NewBufferString(`{}`)); err == nil {
+		t.Fatal("want error for not a json report")
+	}
+}
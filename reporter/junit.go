@@ -17,9 +17,7 @@ package reporter
 
 import (
 	"encoding/xml"
-	"fmt"
 	"io"
-	"reflect"
 
 	"github.com/ysqi/gcodesharp/reporter/formater"
 )
@@ -37,7 +35,6 @@ func (r *Reporter) OutputJunit(noXMLHeader bool, w io.Writer) error {
 	for _, s := range r.services[false] {
 		js, ok := s.(JunitFormater)
 		if !ok {
-			fmt.Printf("SKIP: service %s is not JunitFormater", reflect.TypeOf(s).String())
 			continue
 		}
 		s, err := js.ToJunit()
@@ -16,11 +16,12 @@
 package reporter
 
 import (
+	gocontext "context"
 	"errors"
 	"fmt"
-	"io"
 	"reflect"
 	"sync"
+	"time"
 )
 
 // Reporter is a report service manager.
@@ -34,6 +35,16 @@ type Reporter struct {
 	running        bool
 	serviceProcess sync.WaitGroup
 
+	created time.Time // the time when reporter started
+	cost    float32   // the seconds of all services run
+
+	parent     gocontext.Context // the ctx of Start
+	cancel     gocontext.CancelFunc
+	failed     map[reflect.Type]error // the error of failed services
+	failedLock sync.Mutex
+	err        error // the error of last run
+	artifacts  *Artifacts
+
 	sync.RWMutex
 }
 
@@ -63,9 +74,11 @@ func (r *Reporter) Register(constructor ServiceConstructor) error {
 	return nil
 }
 
-// Start reporter and service.
+// Start reporter and run each of services in background, call Wait to wait for all services done.
+// the service runs after its dependencies done, see Node.
+// the services are canceled when ctx is done or any service failed, e.g: timeout or interrupted.
 // disable start the report without service or is running,otherwise return error.
-func (r *Reporter) Start() error {
+func (r *Reporter) Start(ctx gocontext.Context) error {
 	r.Lock()
 	defer r.Unlock()
 	if r.running {
@@ -90,79 +103,129 @@ func (r *Reporter) Start() error {
 		checkKind[kind] = struct{}{}
 		r.services[false] = append(r.services[false], service)
 	}
+	deps, err := dependencies(r.services[false])
+	if err != nil {
+		return ReportActionError{action: "start report", err: err}
+	}
 
 	r.running = true
-
-	// run service one by one
+	r.created = time.Now()
+	r.parent = ctx
+	r.failed = make(map[reflect.Type]error)
+	r.err = nil
+	r.artifacts = NewArtifacts()
+	ctx, r.cancel = gocontext.WithCancel(ctx)
+
+	// run service one by one, the done channel is closed when the service done.
 	r.serviceProcess = sync.WaitGroup{}
-	for _, s := range r.services[false] {
+	done := make([]chan struct{}, len(r.services[false]))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	for i, s := range r.services[false] {
 		r.serviceProcess.Add(1)
 		r.services[true] = append(r.services[true], s)
-		go func(s Service) {
+		go func(s Service, done chan struct{}, deps []chan struct{}) {
 			defer r.serviceProcess.Done()
-			if !r.running {
-				return
+			defer close(done)
+			for _, d := range deps {
+				<-d
 			}
-			if err := trycatch(s.Run); err != nil {
-				go r.Stop()
+			// the dependency failed or canceled, so it is not run.
+			if len(deps) > 0 && ctx.Err() != nil {
 				return
 			}
-			s.Wait()
-		}(s)
+			name := ServiceName(s)
+			r.context.Events.Publish(Event{Kind: EventServiceStarted, Service: name})
+			err := trycatch(func() error { return r.run(ctx, s) })
+			if err != nil {
+				r.fail(s, err)
+			}
+			r.context.Events.Publish(Event{Kind: EventServiceFinished, Service: name, Err: err})
+		}(s, done[i], depChans(done, deps[i]))
 	}
 
 	return nil
 }
 
-// Stop the reporter and each of running service.
-// disable stop the not running reporter,otherwise return error.
-// return the stop error if one service stop failed.
-// Note: this stop action will wait for all service stop done.
-func (r *Reporter) Stop() error {
-	r.Lock()
-	defer r.Unlock()
-	if !r.running {
-		return ErrNotRunning
+func depChans(done []chan struct{}, deps []int) []chan struct{} {
+	var list []chan struct{}
+	for _, i := range deps {
+		list = append(list, done[i])
 	}
-	r.running = false
-	runningError := ReportActionError{action: "stop report"}
-	// stop the each of running service
-	for _, s := range r.services[true] {
-		if err := trycatch(s.Stop); err != nil {
-			runningError.services[reflect.TypeOf(s)] = err
+	return list
+}
+
+// run the service with the artifacts of its dependencies, and put its artifacts if done without error.
+func (r *Reporter) run(ctx gocontext.Context, s Service) error {
+	if c, ok := s.(ArtifactConsumer); ok {
+		if err := c.Consume(r.artifacts); err != nil {
+			return err
 		}
 	}
+	if err := s.Run(ctx); err != nil {
+		return err
+	}
+	if p, ok := s.(ArtifactProducer); ok {
+		p.Produce(r.artifacts)
+	}
+	return nil
+}
 
-	r.serviceProcess.Wait()
-	//r.services = nil
+// fail record the error of service and cancel the other services.
+func (r *Reporter) fail(s Service, err error) {
+	r.failedLock.Lock()
+	r.failed[reflect.TypeOf(s)] = err
+	r.failedLock.Unlock()
+	r.cancel()
+}
 
-	if len(runningError.services) > 0 {
-		return runningError
+// Stop cancel each of running services and wait for them stopped,
+// the result of services is kept.
+// disable stop the not running reporter,otherwise return error.
+func (r *Reporter) Stop() error {
+	r.RLock()
+	running, cancel := r.running, r.cancel
+	r.RUnlock()
+	if !running {
+		return ErrNotRunning
 	}
-
+	cancel()
+	r.Wait()
 	return nil
 }
 
-func (r *Reporter) OutputHTML(writer io.Writer) error {
-	r.Lock()
-	defer r.Unlock()
-	if r.running {
-		return ErrIsRunning
+// Wait blocks the thread until the each of services is done.
+// return the error if any service failed or the ctx of Start is done,
+// the partial result of services can still be reported.
+func (r *Reporter) Wait() error {
+	r.RLock()
+	running := r.running
+	r.RUnlock()
+	if running {
+		r.serviceProcess.Wait()
+		r.done()
 	}
-
-	return nil
+	r.RLock()
+	defer r.RUnlock()
+	return r.err
 }
 
-// Wait blocks the thread until the each of services is stopped.
-func (r *Reporter) Wait() {
+// done mark the reporter is not running and collect the errors of services.
+func (r *Reporter) done() {
 	r.Lock()
+	defer r.Unlock()
 	if !r.running {
-		r.Unlock()
 		return
 	}
-	r.Unlock()
-	r.serviceProcess.Wait()
 	r.running = false
+	r.cost = float32(time.Since(r.created).Seconds())
+	r.cancel()
+
+	runErr := ReportActionError{action: "run report", services: r.failed, err: r.parent.Err()}
+	if runErr.err != nil || len(runErr.services) > 0 {
+		r.err = runErr
+	}
 }
 
 // trycatch try catch panic error
@@ -16,8 +16,10 @@
 package reporter
 
 import (
+	gocontext "context"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -25,13 +27,7 @@ import (
 
 type HelloService struct{}
 
-func (h *HelloService) Run() error {
-	return nil
-}
-func (h *HelloService) Stop() error {
-	return nil
-}
-func (h *HelloService) Wait() error {
+func (h *HelloService) Run(ctx gocontext.Context) error {
 	return nil
 }
 
@@ -44,7 +40,7 @@ func TestReporter_Default(t *testing.T) {
 	r.Register(func(ctx *ServiceContext) (Service, error) {
 		return &HelloService{}, nil
 	})
-	err = r.Start()
+	err = r.Start(gocontext.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -52,7 +48,9 @@ func TestReporter_Default(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	r.Wait()
+	if err := r.Wait(); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestReporter_DuplicateReg(t *testing.T) {
@@ -63,7 +61,7 @@ func TestReporter_DuplicateReg(t *testing.T) {
 	}
 	r.Register(func(ctx *ServiceContext) (Service, error) { return &HelloService{}, nil })
 	r.Register(func(ctx *ServiceContext) (Service, error) { return &HelloService{}, nil })
-	err = r.Start()
+	err = r.Start(gocontext.Background())
 	if err == nil {
 		t.Fatal("want get duplicate error")
 	} else if _, ok := err.(DuplicateError); !ok {
@@ -77,7 +75,7 @@ func TestReporter_ErrorStart(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if err := r.Start(); err == nil {
+	if err := r.Start(gocontext.Background()); err == nil {
 		t.Fatal("want get start failed error")
 	} else if _, ok := err.(ReportActionError); !ok {
 		t.Fatalf("want error type is report action error,but got %+v", err)
@@ -85,20 +83,12 @@ func TestReporter_ErrorStart(t *testing.T) {
 }
 
 type TestService struct {
-	ID       string
-	runHook  func() error
-	stopHook func() error
-	waitHook func() error
+	ID      string
+	runHook func(ctx gocontext.Context) error
 }
 
-func (h *TestService) Run() error {
-	return h.runHook()
-}
-func (h *TestService) Stop() error {
-	return h.stopHook()
-}
-func (h *TestService) Wait() error {
-	return h.waitHook()
+func (h *TestService) Run(ctx gocontext.Context) error {
+	return h.runHook(ctx)
 }
 
 type TestServerA struct {
@@ -111,32 +101,43 @@ type TestServerC struct {
 	*TestService
 }
 
-type hook func() error
+type hook func(ctx gocontext.Context) error
 
-func loadHook(ins Service, run, stop, wait hook) {
+func loadHook(ins Service, run hook) {
 	switch v := ins.(type) {
 	case *TestServerA:
-		v.TestService = &TestService{
-			stopHook: stop,
-			runHook:  run,
-			waitHook: wait,
-		}
+		v.TestService = &TestService{runHook: run}
 	case *TestServerB:
-		v.TestService = &TestService{
-			stopHook: stop,
-			runHook:  run,
-			waitHook: wait,
-		}
+		v.TestService = &TestService{runHook: run}
 	case *TestServerC:
-		v.TestService = &TestService{
-			stopHook: stop,
-			runHook:  run,
-			waitHook: wait,
-		}
+		v.TestService = &TestService{runHook: run}
 	default:
 		fmt.Println("unkonwn!!!")
 	}
 }
+
+var mapLock sync.Mutex
+
+type MyMap map[Service]bool
+
+func (m MyMap) Set(s Service) {
+	mapLock.Lock()
+	m[s] = true
+	mapLock.Unlock()
+}
+
+// registerHooks register the services which run the hook.
+func registerHooks(r *Reporter, services []Service, run func(i int, s Service, ctx gocontext.Context) error) {
+	for i, s := range services {
+		ser := s
+		i := i
+		r.Register(func(ctx *ServiceContext) (Service, error) {
+			loadHook(ser, func(ctx gocontext.Context) error { return run(i, ser, ctx) })
+			return ser, nil
+		})
+	}
+}
+
 func TestReporter_ServiceStart(t *testing.T) {
 	ctx := ServiceContext{}
 	r, err := New(&ctx)
@@ -144,33 +145,18 @@ func TestReporter_ServiceStart(t *testing.T) {
 		t.Fatal(err)
 	}
 	services := []Service{&TestServerA{}, &TestServerB{}, &TestServerC{}}
-	started := make(map[Service]bool)
-	stopped := make(map[Service]bool)
-
-	for _, s := range services {
-		ser := s
-		r.Register(func(ctx *ServiceContext) (Service, error) {
-			loadHook(ser,
-				func() error {
-					started[ser] = true
-					return nil
-				},
-				func() error {
-					stopped[ser] = true
-					return nil
-				},
-				func() error {
-					return nil
-				},
-			)
-			return ser, nil
-		})
-	}
-	err = r.Start()
+	started := MyMap{}
+	registerHooks(r, services, func(i int, s Service, ctx gocontext.Context) error {
+		started.Set(s)
+		return nil
+	})
+	err = r.Start(gocontext.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
-	r.Wait()
+	if err := r.Wait(); err != nil {
+		t.Fatal(err)
+	}
 
 	for _, s := range services {
 		if !started[s] {
@@ -186,40 +172,27 @@ func TestReporter_ServiceErrorStart(t *testing.T) {
 		t.Fatal(err)
 	}
 	services := []Service{&TestServerA{}, &TestServerB{}, &TestServerC{}}
-	started := make(map[Service]bool)
-	stopped := make(map[Service]bool)
-
-	for i, s := range services {
-		ser := s
-		i := i
-		r.Register(func(ctx *ServiceContext) (Service, error) {
-			loadHook(ser,
-				func() error {
-					if i == len(services)-1 {
-						return fmt.Errorf("error %d", i)
-					}
-					started[ser] = true
-					return nil
-				},
-				func() error {
-					stopped[ser] = true
-					return nil
-				},
-				func() error {
-					return nil
-				},
-			)
-			return ser, nil
-		})
-	}
-	err = r.Start()
+	stopped := MyMap{}
+	registerHooks(r, services, func(i int, s Service, ctx gocontext.Context) error {
+		if i == len(services)-1 {
+			return fmt.Errorf("error %d", i)
+		}
+		// the others are canceled by the failed service
+		<-ctx.Done()
+		stopped.Set(s)
+		return nil
+	})
+	err = r.Start(gocontext.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
-	r.Wait()
-	for _, s := range services {
+	err = r.Wait()
+	if err == nil || !strings.Contains(err.Error(), "error 2") {
+		t.Fatalf("want the error of failed service, got %v", err)
+	}
+	for _, s := range services[:len(services)-1] {
 		if !stopped[s] {
-			t.Fatal(reflect.TypeOf(s).String(), "service is not running")
+			t.Fatal(reflect.TypeOf(s).String(), "service need stopped")
 		}
 	}
 }
@@ -231,61 +204,30 @@ func TestReporter_CatchServicePanicErrorStart(t *testing.T) {
 		t.Fatal(err)
 	}
 	services := []Service{&TestServerA{}, &TestServerB{}, &TestServerC{}}
-	started := MyMap{}
 	stopped := MyMap{}
-
-	var panicSer Service
-	for _, s := range services {
-		ser := s
-		r.Register(func(ctx *ServiceContext) (Service, error) {
-			loadHook(ser,
-				func() error {
-					if len(started) == len(services)-1 {
-						panicSer = ser
-						panic("ha ha")
-					}
-					started.Set(ser)
-					return nil
-				},
-				func() error {
-					stopped.Set(ser)
-					return nil
-				},
-				func() error {
-					return nil
-				},
-			)
-			return ser, nil
-		})
-	}
-	err = r.Start()
+	registerHooks(r, services, func(i int, s Service, ctx gocontext.Context) error {
+		if i == 0 {
+			panic("ha ha")
+		}
+		<-ctx.Done()
+		stopped.Set(s)
+		return nil
+	})
+	err = r.Start(gocontext.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
-	r.Wait()
-	for _, s := range services {
-		if !started[s] && s != panicSer {
-			t.Fatal(reflect.TypeOf(s).String(), "service need started")
-		}
-		if stopped[s] && s != panicSer {
-			t.Fatal(reflect.TypeOf(s).String(), "service should not stoped")
-		}
+	err = r.Wait()
+	if err == nil || !strings.Contains(err.Error(), "ha ha") {
+		t.Fatalf("want the panic error, got %v", err)
 	}
-	if stopped[panicSer] {
-		t.Fatal(reflect.TypeOf(panicSer).String(), "service need stoped")
+	for _, s := range services[1:] {
+		if !stopped[s] {
+			t.Fatal(reflect.TypeOf(s).String(), "service need stopped")
+		}
 	}
 }
 
-var mapLock sync.Mutex
-
-type MyMap map[Service]bool
-
-func (m MyMap) Set(s Service) {
-	mapLock.Lock()
-	m[s] = true
-	mapLock.Unlock()
-}
-
 func TestReporter_ServiceStop(t *testing.T) {
 	ctx := ServiceContext{}
 	r, err := New(&ctx)
@@ -293,33 +235,16 @@ func TestReporter_ServiceStop(t *testing.T) {
 		t.Fatal(err)
 	}
 	services := []Service{&TestServerA{}, &TestServerB{}, &TestServerC{}}
-	started := MyMap{}
 	stopped := MyMap{}
-
-	for i, s := range services {
-		ser := s
-		i := i
-		r.Register(func(ctx *ServiceContext) (Service, error) {
-			loadHook(ser,
-				func() error {
-					started.Set(ser)
-					return nil
-				},
-				func() error {
-					stopped.Set(ser)
-					return nil
-				},
-				func() error {
-					if i == len(services)-1 {
-						time.Sleep(10 * time.Minute)
-					}
-					return nil
-				},
-			)
-			return ser, nil
-		})
-	}
-	err = r.Start()
+	registerHooks(r, services, func(i int, s Service, ctx gocontext.Context) error {
+		select {
+		case <-ctx.Done():
+			stopped.Set(s)
+		case <-time.After(10 * time.Minute):
+		}
+		return nil
+	})
+	err = r.Start(gocontext.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -334,4 +259,37 @@ func TestReporter_ServiceStop(t *testing.T) {
 			t.Fatal(reflect.TypeOf(s).String(), "service is running need stoped")
 		}
 	}
+	if err := r.Wait(); err != nil {
+		t.Fatalf("want no error if the reporter is stopped, got %v", err)
+	}
+}
+
+func TestReporter_Timeout(t *testing.T) {
+	r, err := New(&ServiceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	services := []Service{&TestServerA{}, &TestServerB{}}
+	stopped := MyMap{}
+	registerHooks(r, services, func(i int, s Service, ctx gocontext.Context) error {
+		<-ctx.Done()
+		stopped.Set(s)
+		return nil
+	})
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := r.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	err = r.Wait()
+	if err == nil || !strings.Contains(err.Error(), gocontext.DeadlineExceeded.Error()) {
+		t.Fatalf("want deadline exceeded error, got %v", err)
+	}
+	if len(stopped) != len(services) {
+		t.Fatalf("want all services stopped by timeout, got %d", len(stopped))
+	}
+	// the partial result can be reported after timeout
+	if _, err := r.Findings(); err != nil {
+		t.Fatal(err)
+	}
 }
@@ -0,0 +1,239 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+// sarifRootID is the uri base id of project root dir.
+const sarifRootID = "SRCROOT"
+
+// sarifToolURI the information uri of known tools.
+var sarifToolURI = map[string]string{
+	"gofmt":  "https://golang.org/cmd/gofmt/",
+	"golint": "https://github.com/golang/lint",
+	"govet":  "https://golang.org/cmd/vet/",
+	"gotest": "https://golang.org/cmd/go/#hdr-Test_packages",
+}
+
+// OutputSARIF write the findings of all services as a SARIF 2.1.0 log,
+// each tool is a run. the findings of go test are the notifications of tool execution,
+// because a failed test is not a problem of code location.
+func (r *Reporter) OutputSARIF(w io.Writer) error {
+	r.Lock()
+	defer r.Unlock()
+	if r.running {
+		return ErrIsRunning
+	}
+	root := r.rootDir()
+	tools, list := r.findings()
+	log := formater.SARIFLog{
+		Version: formater.SARIFVersion,
+		Schema:  formater.SARIFSchema,
+		Runs:    []formater.SARIFRun{},
+	}
+	for _, tool := range tools {
+		run := formater.SARIFRun{
+			Tool: formater.SARIFTool{Driver: formater.SARIFDriver{
+				Name:           tool,
+				InformationURI: sarifToolURI[tool],
+			}},
+			OriginalURIBaseIDs: map[string]formater.SARIFArtifactLocation{
+				sarifRootID: {URI: fileURI(root) + "/"},
+			},
+			Results: []formater.SARIFResult{},
+		}
+		invocation := formater.SARIFInvocation{ExecutionSuccessful: true}
+		rules := map[string]int{}
+		for _, f := range list {
+			if f.Tool != tool {
+				continue
+			}
+			if tool == "gotest" {
+				n := formater.SARIFNotification{
+					Level:   sarifLevel(f.Severity),
+					Message: formater.SARIFMessage{Text: f.Message},
+				}
+				if f.File != "" {
+					n.Locations = []formater.SARIFLocation{sarifLocation(f, root)}
+				}
+				invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, n)
+				continue
+			}
+			index, ok := rules[f.Rule]
+			if !ok {
+				index = len(run.Tool.Driver.Rules)
+				rules[f.Rule] = index
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule(f))
+			}
+			result := formater.SARIFResult{
+				RuleID:    f.Rule,
+				RuleIndex: index,
+				Level:     sarifLevel(f.Severity),
+				Message:   formater.SARIFMessage{Text: f.Message},
+				PartialFingerprints: map[string]string{
+					"gcodesharp/v1": f.Fingerprint,
+				},
+			}
+			if f.File != "" {
+				result.Locations = []formater.SARIFLocation{sarifLocation(f, root)}
+			}
+			if fix := sarifFix(f, root); fix != nil {
+				result.Fixes = []formater.SARIFFix{*fix}
+			}
+			run.Results = append(run.Results, result)
+		}
+		run.Invocations = []formater.SARIFInvocation{invocation}
+		log.Runs = append(log.Runs, run)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+func sarifRule(f Finding) formater.SARIFRule {
+	rule := formater.SARIFRule{
+		ID:                   f.Rule,
+		DefaultConfiguration: &formater.SARIFRuleConfiguration{Level: sarifLevel(f.Severity)},
+	}
+	switch f.Tool {
+	case "gofmt":
+		rule.ShortDescription = &formater.SARIFMessage{Text: "Go source code is not formatted by gofmt"}
+	case "golint":
+		rule.ShortDescription = &formater.SARIFMessage{Text: "Go coding style problem reported by golint"}
+	case "govet":
+		rule.ShortDescription = &formater.SARIFMessage{Text: "suspicious construct reported by go vet analyzer " + f.Rule}
+		if f.Rule != "vet" && f.Rule != "load" {
+			rule.HelpURI = "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/" + f.Rule
+		}
+	}
+	return rule
+}
+
+// sarifArtifact return the artifact location of file,
+// the file in root dir is relative to the root uri base.
+func sarifArtifact(file, root string) formater.SARIFArtifactLocation {
+	if root != "" {
+		if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return formater.SARIFArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: sarifRootID}
+		}
+	}
+	return formater.SARIFArtifactLocation{URI: fileURI(file)}
+}
+
+func fileURI(file string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(file)}
+	if !strings.HasPrefix(u.Path, "/") {
+		// windows path, e.g: C:/a
+		u.Path = "/" + u.Path
+	}
+	return u.String()
+}
+
+func sarifLocation(f Finding, root string) formater.SARIFLocation {
+	loc := formater.SARIFLocation{PhysicalLocation: formater.SARIFPhysicalLocation{
+		ArtifactLocation: sarifArtifact(f.File, root),
+	}}
+	if f.Line > 0 {
+		loc.PhysicalLocation.Region = &formater.SARIFRegion{
+			StartLine:   f.Line,
+			StartColumn: f.Col,
+			EndLine:     f.EndLine,
+			EndColumn:   f.EndCol,
+		}
+	}
+	return loc
+}
+
+// regDiffHunk match the hunk head of unified diff, e.g: @@ -18,6 +18,7 @@
+var regDiffHunk = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+\d+(?:,\d+)? @@`)
+
+// sarifFix convert the unified diff of finding to fix,
+// each hunk replace the old lines with the new lines.
+// return nil if the finding has no fix or the fix is not a diff.
+func sarifFix(f Finding, root string) *formater.SARIFFix {
+	if f.Fix == "" || f.File == "" {
+		return nil
+	}
+	var (
+		replacements []formater.SARIFReplacement
+		current      *formater.SARIFReplacement
+	)
+	for _, line := range strings.Split(strings.TrimRight(f.Fix, "\n"), "\n") {
+		if matches := regDiffHunk.FindStringSubmatch(line); matches != nil {
+			start, _ := strconv.Atoi(matches[1])
+			count := 1
+			if matches[2] != "" {
+				count, _ = strconv.Atoi(matches[2])
+			}
+			if count == 0 {
+				// insert after the start line
+				start++
+			}
+			// delete the whole lines with line break.
+			replacements = append(replacements, formater.SARIFReplacement{
+				DeletedRegion: formater.SARIFRegion{
+					StartLine:   start,
+					StartColumn: 1,
+					EndLine:     start + count,
+					EndColumn:   1,
+				},
+				InsertedContent: &formater.SARIFArtifactContent{},
+			})
+			current = &replacements[len(replacements)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch {
+		case line == "":
+			// the blank context line may be trimmed
+			current.InsertedContent.Text += "\n"
+		case line[0] == ' ' || line[0] == '+':
+			current.InsertedContent.Text += line[1:] + "\n"
+		}
+	}
+	if len(replacements) == 0 {
+		return nil
+	}
+	return &formater.SARIFFix{
+		Description: &formater.SARIFMessage{Text: "apply the suggested change of " + f.Tool},
+		ArtifactChanges: []formater.SARIFArtifactChange{{
+			ArtifactLocation: sarifArtifact(f.File, root),
+			Replacements:     replacements,
+		}},
+	}
+}
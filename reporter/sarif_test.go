@@ -0,0 +1,104 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ysqi/gcodesharp/reporter/formater"
+)
+
+type SARIFService struct {
+	HelloService
+	tool     string
+	findings []Finding
+}
+
+func (s *SARIFService) Tool() string        { return s.tool }
+func (s *SARIFService) Findings() []Finding { return s.findings }
+
+// the reporter disable register the same type services.
+type (
+	FmtService    struct{ SARIFService }
+	GoTestService struct{ SARIFService }
+	LintService   struct{ SARIFService }
+)
+
+func TestReporter_OutputSARIF(t *testing.T) {
+	r, err := New(&ServiceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := "--- a.go.orig\n+++ a.go\n@@ -3,3 +3,3 @@\n import \"fmt\"\n-func A(){\n+func A() {\n \n"
+	services := []Service{
+		&FmtService{SARIFService{tool: "gofmt", findings: []Finding{{Tool: "gofmt", Rule: "gofmt", Severity: SeverityWarning, File: "/p/a.go", Line: 3, Message: "file is not gofmt-ed", Fix: diff}}}},
+		&GoTestService{SARIFService{tool: "gotest", findings: []Finding{{Tool: "gotest", Rule: "TestA", Severity: SeverityError, Message: "p.TestA failed"}}}},
+		&LintService{SARIFService{tool: "golint"}},
+	}
+	for _, s := range services {
+		s := s
+		r.Register(func(ctx *ServiceContext) (Service, error) { return s, nil })
+	}
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+
+	var buf bytes.Buffer
+	if err := r.OutputSARIF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var log formater.SARIFLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 3 {
+		t.Fatalf("want 3 runs of sarif 2.1.0, got %s and %d runs", log.Version, len(log.Runs))
+	}
+
+	gofmt := log.Runs[0]
+	if gofmt.Tool.Driver.Name != "gofmt" || len(gofmt.Tool.Driver.Rules) != 1 || len(gofmt.Results) != 1 {
+		t.Fatalf("want a gofmt run with a rule and a result, got %+v", gofmt)
+	}
+	res := gofmt.Results[0]
+	if loc := res.Locations[0].PhysicalLocation; loc.Region.StartLine != 3 || loc.ArtifactLocation.URI == "" {
+		t.Fatalf("want result location at line 3, got %+v", loc)
+	}
+	if res.PartialFingerprints["gcodesharp/v1"] == "" {
+		t.Fatalf("want the gcodesharp/v1 fingerprint, got %v", res.PartialFingerprints)
+	}
+	if len(res.Fixes) != 1 {
+		t.Fatalf("want a fix from gofmt diff, got %d", len(res.Fixes))
+	}
+	rep := res.Fixes[0].ArtifactChanges[0].Replacements[0]
+	if rep.DeletedRegion.StartLine != 3 || rep.DeletedRegion.EndLine != 6 {
+		t.Fatalf("want replace line 3 to 5, got %+v", rep.DeletedRegion)
+	}
+	if want := "import \"fmt\"\nfunc A() {\n\n"; rep.InsertedContent.Text != want {
+		t.Fatalf("want inserted content %q, got %q", want, rep.InsertedContent.Text)
+	}
+
+	gotest := log.Runs[1]
+	if len(gotest.Results) != 0 || len(gotest.Invocations[0].ToolExecutionNotifications) != 1 {
+		t.Fatalf("want failed test as notification, got %+v", gotest)
+	}
+	if golint := log.Runs[2]; golint.Tool.Driver.Name != "golint" || golint.Results == nil {
+		t.Fatalf("want a empty golint run, got %+v", golint)
+	}
+}
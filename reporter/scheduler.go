@@ -0,0 +1,158 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	gocontext "context"
+	"runtime"
+	"sync"
+)
+
+// Scheduler run the jobs of services with a bounded number of workers,
+// e.g: a job is the go test of a package, so the machine is not thrashed by hundreds of processes.
+// the jobs of tools are run in turn, so a tool with many jobs does not starve the others,
+// and the running jobs of a tool can be capped.
+//
+// a nil Scheduler runs each job in its own goroutine without limit.
+type Scheduler struct {
+	limit int            // the max number of running jobs
+	caps  map[string]int // the max number of running jobs of tool, no cap if not set
+
+	mu      sync.Mutex
+	tools   []string // the tools in order of first submitted
+	next    int      // the index of tool which is picked first, it is after the last picked tool
+	queues  map[string][]*job
+	running map[string]int // the number of running jobs of tool
+	total   int            // the number of running jobs
+}
+
+type job struct {
+	ctx  gocontext.Context
+	tool string
+	run  func()
+	done func()
+}
+
+// NewScheduler return a scheduler which runs limit jobs at the same time,
+// the limit is GOMAXPROCS if it is not positive. caps is the max running jobs of each tool.
+func NewScheduler(limit int, caps map[string]int) *Scheduler {
+	if limit <= 0 {
+		limit = runtime.GOMAXPROCS(0)
+	}
+	return &Scheduler{
+		limit:   limit,
+		caps:    caps,
+		queues:  make(map[string][]*job),
+		running: make(map[string]int),
+	}
+}
+
+// Limit return the max number of running jobs.
+func (s *Scheduler) Limit() int {
+	return s.limit
+}
+
+// Group return a group to submit the jobs of tool, e.g: gtest.
+// the job is skipped if ctx is done before it started.
+func (s *Scheduler) Group(ctx gocontext.Context, tool string) *Group {
+	return &Group{s: s, ctx: ctx, tool: tool}
+}
+
+func (s *Scheduler) submit(j *job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.queues[j.tool]; !ok {
+		s.tools = append(s.tools, j.tool)
+	}
+	s.queues[j.tool] = append(s.queues[j.tool], j)
+	s.dispatch()
+}
+
+// dispatch start the queued jobs while there is a free worker, the lock must be held.
+func (s *Scheduler) dispatch() {
+	for s.total < s.limit {
+		j := s.pick()
+		if j == nil {
+			return
+		}
+		s.total++
+		s.running[j.tool]++
+		go s.exec(j)
+	}
+}
+
+// pick return the next job by round robin of tools, the tool which reaches its cap is skipped.
+// return nil if no job can be run.
+func (s *Scheduler) pick() *job {
+	for i := range s.tools {
+		index := (s.next + i) % len(s.tools)
+		tool := s.tools[index]
+		queue := s.queues[tool]
+		if len(queue) == 0 {
+			continue
+		}
+		if max, ok := s.caps[tool]; ok && max > 0 && s.running[tool] >= max {
+			continue
+		}
+		s.queues[tool] = queue[1:]
+		// not wrap it here, the tool which is submitted later is the next one.
+		s.next = index + 1
+		return queue[0]
+	}
+	return nil
+}
+
+func (s *Scheduler) exec(j *job) {
+	defer func() {
+		s.mu.Lock()
+		s.total--
+		s.running[j.tool]--
+		s.dispatch()
+		s.mu.Unlock()
+		j.done()
+	}()
+	if j.ctx.Err() == nil {
+		j.run()
+	}
+}
+
+// Group is the jobs of a tool which are submitted to scheduler.
+type Group struct {
+	s    *Scheduler
+	ctx  gocontext.Context
+	tool string
+	wg   sync.WaitGroup
+}
+
+// Go submit the job, it is run when a worker is free.
+func (g *Group) Go(run func()) {
+	g.wg.Add(1)
+	if g.s == nil {
+		go func() {
+			defer g.wg.Done()
+			if g.ctx.Err() == nil {
+				run()
+			}
+		}()
+		return
+	}
+	g.s.submit(&job{ctx: g.ctx, tool: g.tool, run: run, done: g.wg.Done})
+}
+
+// Wait blocks until all jobs of group are done or skipped.
+func (g *Group) Wait() {
+	g.wg.Wait()
+}
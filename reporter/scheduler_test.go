@@ -0,0 +1,142 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	gocontext "context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// runJobs submit n jobs of each tool, return the max number of running jobs of all and each tool.
+func runJobs(s *Scheduler, n int, tools ...string) (int, map[string]int) {
+	var (
+		mu            sync.Mutex
+		running, max  int
+		byTool, maxBy = map[string]int{}, map[string]int{}
+	)
+	var groups []*Group
+	for _, tool := range tools {
+		g := s.Group(gocontext.Background(), tool)
+		groups = append(groups, g)
+		for i := 0; i < n; i++ {
+			tool := tool
+			g.Go(func() {
+				mu.Lock()
+				running++
+				byTool[tool]++
+				if running > max {
+					max = running
+				}
+				if byTool[tool] > maxBy[tool] {
+					maxBy[tool] = byTool[tool]
+				}
+				mu.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				mu.Lock()
+				running--
+				byTool[tool]--
+				mu.Unlock()
+			})
+		}
+	}
+	for _, g := range groups {
+		g.Wait()
+	}
+	return max, maxBy
+}
+
+func TestScheduler_Limit(t *testing.T) {
+	if s := NewScheduler(0, nil); s.Limit() < 1 {
+		t.Fatalf("want the default limit is GOMAXPROCS, got %d", s.Limit())
+	}
+	max, _ := runJobs(NewScheduler(3, nil), 10, "gtest", "gfmt")
+	if max > 3 {
+		t.Fatalf("want at most 3 running jobs, got %d", max)
+	}
+	if max < 2 {
+		t.Fatalf("want jobs run in parallel, got %d", max)
+	}
+}
+
+func TestScheduler_ToolCap(t *testing.T) {
+	max, maxBy := runJobs(NewScheduler(4, map[string]int{"gtest": 1}), 6, "gtest", "gvet")
+	if max > 4 {
+		t.Fatalf("want at most 4 running jobs, got %d", max)
+	}
+	if maxBy["gtest"] != 1 {
+		t.Fatalf("want at most 1 running gtest job, got %d", maxBy["gtest"])
+	}
+	if maxBy["gvet"] < 2 {
+		t.Fatalf("want gvet use the free workers, got %d", maxBy["gvet"])
+	}
+}
+
+func TestScheduler_Fair(t *testing.T) {
+	var (
+		s     = NewScheduler(1, nil)
+		mu    sync.Mutex
+		order []string
+		start = make(chan struct{})
+	)
+	job := func(name string, block bool) func() {
+		return func() {
+			if block {
+				<-start
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+	gtest := s.Group(gocontext.Background(), "gtest")
+	gfmt := s.Group(gocontext.Background(), "gfmt")
+	// the first job holds the only worker until all jobs are submitted.
+	gtest.Go(job("t1", true))
+	gtest.Go(job("t2", false))
+	gtest.Go(job("t3", false))
+	gfmt.Go(job("f1", false))
+	gfmt.Go(job("f2", false))
+	close(start)
+	gtest.Wait()
+	gfmt.Wait()
+
+	want := []string{"t1", "f1", "t2", "f2", "t3"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("want tools run in turn %v, got %v", want, order)
+	}
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	for _, s := range []*Scheduler{NewScheduler(1, nil), nil} {
+		ctx, cancel := gocontext.WithCancel(gocontext.Background())
+		g := s.Group(ctx, "gtest")
+		count := 0
+		g.Go(func() {
+			cancel()
+			count++
+		})
+		// wait the first job, the nil scheduler runs jobs at once.
+		time.Sleep(10 * time.Millisecond)
+		g.Go(func() { count++ })
+		g.Wait()
+		if count != 1 {
+			t.Fatalf("want the job is skipped after ctx done, run %d jobs", count)
+		}
+	}
+}
@@ -16,8 +16,10 @@
 package reporter
 
 import (
+	gocontext "context"
 	"io"
 
+	"github.com/ysqi/gcodesharp/config"
 	"github.com/ysqi/gcodesharp/context"
 
 	"github.com/spf13/pflag"
@@ -39,9 +41,10 @@ type TextPlainGenerate interface {
 
 // Service a report service interface
 type Service interface {
-	Run() error
-	Stop() error
-	Wait() error
+	// Run the service and block until it is done.
+	// the service must kill its processes when ctx is done and keep the partial result,
+	// the error is only returned if the service self failed.
+	Run(ctx gocontext.Context) error
 }
 
 // ServiceContext is a context for report work
@@ -52,6 +55,15 @@ type ServiceContext struct {
 	// list of program run arg
 	Flagset *pflag.FlagSet
 
+	// Config the project config, service read its options by Config.Section.
+	Config *config.Config
+
+	// Scheduler is shared by all services, the service submit the job of each package to it.
+	Scheduler *Scheduler
+
+	// Events streams the progress of reporter and services, see EventBus.
+	Events *EventBus
+
 	ErrH func(fm string, args ...interface{})
 }
 
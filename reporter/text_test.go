@@ -0,0 +1,59 @@
+// Copyright (C) 2017. author ysqi(devysq@gmail.com).
+//
+// The gcodesharp is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The gcodesharp is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package reporter
+
+import (
+	"bytes"
+	gocontext "context"
+	"io"
+	"testing"
+)
+
+type TextService struct {
+	HelloService
+	text string
+}
+
+func (h *TextService) TOutput(w io.Writer) error {
+	_, err := io.WriteString(w, h.text)
+	return err
+}
+
+type TextService2 struct {
+	TextService
+}
+
+func TestReporter_OutputText(t *testing.T) {
+	r, err := New(&ServiceContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &TextService{text: "first\n"}, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &HelloService{}, nil })
+	r.Register(func(ctx *ServiceContext) (Service, error) { return &TextService2{TextService{text: "second\n"}}, nil })
+	if err := r.Start(gocontext.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Wait()
+
+	var buf bytes.Buffer
+	if err := r.OutputText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "first\n\nsecond\n"; buf.String() != want {
+		t.Fatalf("want text report %q, got %q", want, buf.String())
+	}
+}